@@ -15,13 +15,363 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/circuit-breakers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Shows the state of the circuit breaker of every backend service",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Lists circuit breakers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/resilience.BreakerState"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/log-level": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the level below which log records are dropped",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Shows the log level",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LogLevel"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets the log level (debug, info, warn or error) without a restart",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Changes the log level",
+                "parameters": [
+                    {
+                        "description": "New log level",
+                        "name": "level",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.LogLevel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LogLevel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid log level",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Reports that the gateway process is up",
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/item-system/auth/login": {
+            "post": {
+                "description": "Checks user credentials and returns access and refresh tokens",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logs a user in",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authentication.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/authentication.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error while logging in",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/item-system/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes the refresh token of the user",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logs a user out",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "User info",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authentication.LogoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/authentication.LogoutResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error while logging out",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/item-system/auth/refresh": {
+            "post": {
+                "description": "Checks the refresh token and issues a new access token",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refreshes an access token",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authentication.CheckRefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/authentication.CheckRefreshTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error while refreshing token",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/item-system/auth/register": {
+            "post": {
+                "description": "Creates a new user account in the authentication service",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Registers a new user",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "User details",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authentication.UserDetails"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/authentication.ID"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error while registering user",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/item-system/auth/reset-password": {
+            "post": {
+                "description": "Sends a password reset message to the user email",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resets user password",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "User email",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authentication.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/authentication.ResetPasswordResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error while resetting password",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    }
+                }
+            }
+        },
         "/item-system/category/catogories": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Inserts new item category info into item_categories table in PostgreSQL",
                 "tags": [
                     "item"
                 ],
                 "summary": "Adds an item category",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "New data",
@@ -43,13 +393,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while adding item category",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -57,11 +413,17 @@ const docTemplate = `{
         },
         "/item-system/eco-tips": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Retrieves all eco tips info from PostgreSQL",
                 "tags": [
                     "eco_tip"
                 ],
                 "summary": "Gets all eco tips",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "Request data",
@@ -83,17 +445,29 @@ const docTemplate = `{
                     "500": {
                         "description": "Server error while getting eco tips",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
             },
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Inserts new eco tip info into eco_tips table in PostgreSQL",
                 "tags": [
                     "eco_tip"
                 ],
                 "summary": "Creates a new eco tip",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "New data",
@@ -115,13 +489,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while creating eco tip",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -129,11 +509,17 @@ const docTemplate = `{
         },
         "/item-system/ecosystem/eco-challenge": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Inserts new eco challenge info into eco_challenges table in PostgreSQL",
                 "tags": [
                     "eco_challenge"
                 ],
                 "summary": "Creates a new eco challenge",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "New data",
@@ -155,13 +541,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while creating eco challenge",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -169,11 +561,17 @@ const docTemplate = `{
         },
         "/item-system/ecosystem/participate": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Inserts new participation info into challenge_participations table in PostgreSQL",
                 "tags": [
                     "eco_challenge"
                 ],
                 "summary": "Participates in an eco challenge",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "New data",
@@ -195,13 +593,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while participating in eco challenge",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -209,11 +613,17 @@ const docTemplate = `{
         },
         "/item-system/ecosystem/update": {
             "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Updates progress info in challenge_participations table in PostgreSQL",
                 "tags": [
                     "eco_challenge"
                 ],
                 "summary": "Updates progress in an eco challenge",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "New data",
@@ -235,13 +645,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while updating eco challenge progress",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -249,11 +665,17 @@ const docTemplate = `{
         },
         "/item-system/items": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Retrieves all items info from items table in PostgreSQL",
                 "tags": [
                     "item"
                 ],
                 "summary": "Lists all items",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "list item data",
@@ -275,7 +697,13 @@ const docTemplate = `{
                     "500": {
                         "description": "Server error while listing items",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -283,11 +711,17 @@ const docTemplate = `{
         },
         "/item-system/items/addItem": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Inserts new item info into items table in PostgreSQL",
                 "tags": [
                     "item"
                 ],
                 "summary": "Adds a new item",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "New item data",
@@ -309,13 +743,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while adding item",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -323,11 +763,17 @@ const docTemplate = `{
         },
         "/item-system/items/search": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Searches items info in items table in PostgreSQL",
                 "tags": [
                     "item"
                 ],
                 "summary": "Searches for items",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "list item data",
@@ -349,7 +795,13 @@ const docTemplate = `{
                     "500": {
                         "description": "Server error while searching items",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -357,11 +809,17 @@ const docTemplate = `{
         },
         "/item-system/items/{item_id}": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Retrieves item info from items table in PostgreSQL",
                 "tags": [
                     "item"
                 ],
                 "summary": "Gets an item",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -381,23 +839,35 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid item ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while getting item",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
             },
             "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Updates item info in items table in PostgreSQL",
                 "tags": [
                     "item"
                 ],
                 "summary": "Updates an item",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -426,23 +896,35 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while updating item",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
             },
             "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Deletes item from items table in PostgreSQL",
                 "tags": [
                     "item"
                 ],
                 "summary": "Deletes an item",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -462,13 +944,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid item ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while deleting item",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -476,11 +964,17 @@ const docTemplate = `{
         },
         "/item-system/ratings/GetAll": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Retrieves all ratings info from ratings table in PostgreSQL",
                 "tags": [
                     "rating"
                 ],
                 "summary": "Gets all ratings",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "rating data",
@@ -502,7 +996,13 @@ const docTemplate = `{
                     "500": {
                         "description": "Server error while getting ratings",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -510,11 +1010,17 @@ const docTemplate = `{
         },
         "/item-system/ratings/add": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Inserts new rating info into ratings table in PostgreSQL",
                 "tags": [
                     "rating"
                 ],
                 "summary": "Adds a new rating",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "New rating data",
@@ -536,25 +1042,37 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while adding rating",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
             }
         },
-        "/item-system/recycling": {
-            "post": {
+        "/item-system/recyclings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Inserts recycling submission info into the database",
                 "tags": [
                     "recycling"
                 ],
                 "summary": "Submits items for recycling",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "New recycling submission data",
@@ -576,25 +1094,35 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while submitting items for recycling",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
-            }
-        },
-        "/item-system/recycling-centers": {
+            },
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Inserts new recycling center info into the database",
                 "tags": [
                     "recycling_center"
                 ],
                 "summary": "Adds a new recycling center",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "New recycling center data",
@@ -616,25 +1144,37 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while adding recycling center",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
             }
         },
-        "/item-system/recycling-centers/search": {
-            "post": {
+        "/item-system/recyclings/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Retrieves recycling centers based on search criteria",
                 "tags": [
                     "recycling_center"
                 ],
                 "summary": "Searches for recycling centers",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "Search criteria",
@@ -656,13 +1196,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid search criteria",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while searching recycling centers",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -670,11 +1216,17 @@ const docTemplate = `{
         },
         "/item-system/statistics": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Retrieves various statistics from the service",
                 "tags": [
                     "statistics"
                 ],
                 "summary": "Gets statistics",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "Statistics filter",
@@ -696,25 +1248,37 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid filter",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while getting statistics",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
             }
         },
-        "/item-system/swaps": {
+        "/item-system/swaps/": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Sends a swap request to the service",
                 "tags": [
                     "swap"
                 ],
                 "summary": "Send swap request",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "Swap request info",
@@ -736,13 +1300,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid request data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while sending swap request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -750,11 +1320,17 @@ const docTemplate = `{
         },
         "/item-system/swaps/accept": {
             "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Accepts a swap request",
                 "tags": [
                     "swap"
                 ],
                 "summary": "Accept swap request",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "Swap request info",
@@ -776,33 +1352,45 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid request data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while accepting swap request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
             }
         },
-        "/item-system/swaps/list": {
-            "post": {
-                "description": "Lists all swap requests",
+        "/item-system/swaps/reject": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rejects a swap request",
                 "tags": [
                     "swap"
                 ],
-                "summary": "List swap requests",
+                "summary": "Reject swap request",
+                "deprecated": true,
                 "parameters": [
                     {
-                        "description": "Swap request filter",
-                        "name": "filter",
+                        "description": "Swap request info",
+                        "name": "swap",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/item.ListSwapRequestsRequest"
+                            "$ref": "#/definitions/item.RejectSwapRequestRequest"
                         }
                     }
                 ],
@@ -810,39 +1398,58 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/item.ListSwapRequestsResponse"
+                            "$ref": "#/definitions/item.SwapResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid filter",
+                        "description": "Invalid request data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
-                        "description": "Server error while listing swap requests",
+                        "description": "Server error while rejecting swap request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
             }
         },
-        "/item-system/swaps/reject": {
+        "/item-system/swaps/{swap_id}": {
             "put": {
-                "description": "Rejects a swap request",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists all swap requests",
                 "tags": [
                     "swap"
                 ],
-                "summary": "Reject swap request",
+                "summary": "List swap requests",
+                "deprecated": true,
                 "parameters": [
                     {
-                        "description": "Swap request info",
-                        "name": "swap",
+                        "type": "string",
+                        "description": "Not read; the filter is in the body",
+                        "name": "swap_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Swap request filter",
+                        "name": "filter",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/item.RejectSwapRequestRequest"
+                            "$ref": "#/definitions/item.ListSwapRequestsRequest"
                         }
                     }
                 ],
@@ -850,19 +1457,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/item.SwapResponse"
+                            "$ref": "#/definitions/item.ListSwapRequestsResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request data",
+                        "description": "Invalid filter",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
-                        "description": "Server error while rejecting swap request",
+                        "description": "Server error while listing swap requests",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -870,11 +1483,17 @@ const docTemplate = `{
         },
         "/item-system/users": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Retrieves list of users from PostgreSQL",
                 "tags": [
                     "user"
                 ],
                 "summary": "Gets list of users",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "filter user info",
@@ -899,7 +1518,13 @@ const docTemplate = `{
                     "500": {
                         "description": "Server error while getting users",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -907,11 +1532,17 @@ const docTemplate = `{
         },
         "/item-system/users/{user_id}": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Retrieves user profile info from PostgreSQL",
                 "tags": [
                     "user"
                 ],
                 "summary": "Gets user profile",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -931,23 +1562,35 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid user ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while getting user profile",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
             },
             "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Updates user profile info in PostgreSQL",
                 "tags": [
                     "user"
                 ],
                 "summary": "Updates user profile",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -976,23 +1619,35 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid user ID or data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while updating user profile",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
             },
             "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Removes user info from PostgreSQL",
                 "tags": [
                     "user"
                 ],
                 "summary": "Deletes a user",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -1012,13 +1667,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid user ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while deleting user",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -1026,11 +1687,17 @@ const docTemplate = `{
         },
         "/item-system/users/{user_id}/eco-points": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Retrieves eco points info from PostgreSQL",
                 "tags": [
                     "user"
                 ],
                 "summary": "Gets eco points of a user",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -1050,23 +1717,35 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid user ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while getting eco points",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
             },
             "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Inserts eco points info into PostgreSQL",
                 "tags": [
                     "user"
                 ],
                 "summary": "Adds eco points to a user",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -1095,13 +1774,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid user ID or data",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while adding eco points",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     }
                 }
@@ -1109,11 +1794,17 @@ const docTemplate = `{
         },
         "/item-system/users/{user_id}/eco-points/history": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Retrieves eco points history from PostgreSQL",
                 "tags": [
                     "user"
                 ],
                 "summary": "Gets eco points history of a user",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -1145,13 +1836,42 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid user ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
                         }
                     },
                     "500": {
                         "description": "Server error while getting eco points history",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    },
+                    "504": {
+                        "description": "Backend did not respond in time",
+                        "schema": {
+                            "$ref": "#/definitions/response.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Checks the connection and gRPC health of every backend service",
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
                         }
                     }
                 }
@@ -1159,6 +1879,169 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "authentication.AccessToken": {
+            "type": "object",
+            "properties": {
+                "accesstoken": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "authentication.CheckRefreshTokenRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "authentication.CheckRefreshTokenResponse": {
+            "type": "object",
+            "properties": {
+                "acces": {
+                    "type": "boolean"
+                },
+                "accestoken": {
+                    "type": "string"
+                }
+            }
+        },
+        "authentication.ID": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "authentication.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "authentication.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access": {
+                    "$ref": "#/definitions/authentication.AccessToken"
+                },
+                "refresh": {
+                    "$ref": "#/definitions/authentication.RefreshToken"
+                }
+            }
+        },
+        "authentication.LogoutRequest": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "authentication.LogoutResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "authentication.RefreshToken": {
+            "type": "object",
+            "properties": {
+                "refreshtoken": {
+                    "type": "string"
+                },
+                "userid": {
+                    "type": "string"
+                }
+            }
+        },
+        "authentication.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "authentication.ResetPasswordResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "authentication.UserDetails": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.LogLevel": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "example": "INFO"
+                }
+            }
+        },
+        "health.Dependency": {
+            "type": "object",
+            "properties": {
+                "connection": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "health": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.Dependency"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
         "item.AcceptSwapRequestRequest": {
             "type": "object",
             "properties": {
@@ -1900,6 +2783,56 @@ const docTemplate = `{
                 },
                 "swap_preference": {
                     "type": "string"
+                },
+                "update_mask": {
+                    "type": "string"
+                }
+            }
+        },
+        "resilience.BreakerState": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "opened_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.Envelope": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/response.ErrorBody"
+                }
+            }
+        },
+        "response.ErrorBody": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "NOT_FOUND"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "item not found"
+                },
+                "request_id": {
+                    "type": "string"
                 }
             }
         },
@@ -2112,6 +3045,9 @@ const docTemplate = `{
                 "full_name": {
                     "type": "string"
                 },
+                "update_mask": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "string"
                 },
@@ -2137,6 +3073,13 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
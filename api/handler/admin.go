@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"api-gateway/api/response"
+	"api-gateway/pkg/resilience"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+// CircuitBreakers godoc
+// @Summary Lists circuit breakers
+// @Description Shows the state of the circuit breaker of every backend service
+// @Tags admin
+// @Success 200 {object} []resilience.BreakerState
+// @Security BearerAuth
+// @Router /admin/circuit-breakers [get]
+func (h *Handler) CircuitBreakers(c *gin.Context) {
+	states := make([]resilience.BreakerState, 0, len(h.Breakers))
+	for _, breaker := range h.Breakers {
+		states = append(states, breaker.Snapshot())
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+
+	c.JSON(http.StatusOK, states)
+}
+
+type LogLevel struct {
+	Level string `json:"level" example:"INFO"`
+}
+
+// GetLogLevel godoc
+// @Summary Shows the log level
+// @Description Returns the level below which log records are dropped
+// @Tags admin
+// @Success 200 {object} LogLevel
+// @Security BearerAuth
+// @Router /admin/log-level [get]
+func (h *Handler) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, LogLevel{Level: h.LogLevel.Level().String()})
+}
+
+// SetLogLevel godoc
+// @Summary Changes the log level
+// @Description Sets the log level (debug, info, warn or error) without a restart
+// @Tags admin
+// @Param level body LogLevel true "New log level"
+// @Success 200 {object} LogLevel
+// @Failure 400 {object} response.Envelope "Invalid log level"
+// @Security BearerAuth
+// @Router /admin/log-level [put]
+func (h *Handler) SetLogLevel(c *gin.Context) {
+	var req LogLevel
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.Error(c, codes.InvalidArgument, "invalid data")
+		return
+	}
+
+	var level slog.Level
+	err = level.UnmarshalText([]byte(req.Level))
+	if err != nil {
+		response.Error(c, codes.InvalidArgument, "unknown log level "+req.Level)
+		return
+	}
+
+	previous := h.LogLevel.Level()
+	h.LogLevel.Set(level)
+	h.requestLogger(c).Warn("log level changed", "from", previous.String(), "to", level.String())
+
+	c.JSON(http.StatusOK, LogLevel{Level: level.String()})
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"api-gateway/api/middleware"
+)
+
+// Binder fills part of a request message from the HTTP request.
+type Binder func(c *gin.Context, msg proto.Message) error
+
+// Path copies every path parameter into the message field of the same name.
+func Path(c *gin.Context, msg proto.Message) error {
+	for _, param := range c.Params {
+		err := SetField(msg, param.Key, param.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Query copies query parameters into message fields, matching either the
+// proto or the JSON field name. Dotted names address nested messages.
+func Query(c *gin.Context, msg proto.Message) error {
+	for key, values := range c.Request.URL.Query() {
+		for _, value := range values {
+			err := SetField(msg, key, value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Subject sets the field to the user ID of the verified access token,
+// overriding whatever the client sent.
+func Subject(field string) Binder {
+	return func(c *gin.Context, msg proto.Message) error {
+		userID := middleware.UserID(c)
+		if userID == "" {
+			return status.Error(codes.Unauthenticated, "authentication required")
+		}
+		return SetField(msg, field, userID)
+	}
+}
+
+// SetField parses value according to the type of the named field and
+// stores it. Repeated fields get the value appended. Names that match no
+// field are ignored.
+func SetField(msg proto.Message, name, value string) error {
+	m := msg.ProtoReflect()
+	path := strings.Split(name, ".")
+
+	for _, part := range path[:len(path)-1] {
+		fd := fieldByName(m.Descriptor(), part)
+		if fd == nil || fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return nil
+		}
+		m = m.Mutable(fd).Message()
+	}
+
+	fd := fieldByName(m.Descriptor(), path[len(path)-1])
+	if fd == nil || fd.IsMap() {
+		return nil
+	}
+
+	v, err := parseScalar(fd, value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid value for %s", name)
+	}
+
+	if fd.IsList() {
+		m.Mutable(fd).List().Append(v)
+		return nil
+	}
+	m.Set(fd, v)
+	return nil
+}
+
+func fieldByName(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fd := md.Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		fd = md.Fields().ByJSONName(name)
+	}
+	return fd
+}
+
+func parseScalar(fd protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(value)), nil
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.EnumKind:
+		ev := fd.Enum().Values().ByName(protoreflect.Name(value))
+		if ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		v, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	}
+	return protoreflect.Value{}, errors.Errorf("unsupported field type %s", fd.Kind())
+}
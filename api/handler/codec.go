@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"api-gateway/config"
+)
+
+// Codec converts between HTTP JSON bodies and protobuf messages following
+// the proto3 JSON mapping.
+type Codec struct {
+	Marshal   protojson.MarshalOptions
+	Unmarshal protojson.UnmarshalOptions
+}
+
+func NewCodec(cfg *config.Config) Codec {
+	return Codec{
+		Marshal: protojson.MarshalOptions{
+			EmitUnpopulated: cfg.PROTOJSON_EMIT_UNPOPULATED,
+			UseProtoNames:   cfg.PROTOJSON_USE_PROTO_NAMES,
+		},
+		Unmarshal: protojson.UnmarshalOptions{
+			DiscardUnknown: cfg.PROTOJSON_DISCARD_UNKNOWN,
+		},
+	}
+}
+
+// Body decodes the JSON request body into the message. Fields already set
+// by earlier binders are kept unless the body overrides them, and an
+// empty body leaves the message untouched.
+func (h *Handler) Body(c *gin.Context, msg proto.Message) error {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	decoded := msg.ProtoReflect().New().Interface()
+	err = h.Codec.Unmarshal.Unmarshal(data, decoded)
+	if err != nil {
+		return err
+	}
+
+	proto.Merge(msg, decoded)
+	return nil
+}
+
+// ProtoJSON renders a message with the handler codec.
+func (h *Handler) ProtoJSON(c *gin.Context, code int, msg proto.Message) {
+	c.Render(code, protoRender{opts: h.Codec.Marshal, msgs: []proto.Message{msg}})
+}
+
+// ProtoJSONList renders messages as a JSON array.
+func ProtoJSONList[T proto.Message](h *Handler, c *gin.Context, code int, msgs []T) {
+	list := make([]proto.Message, len(msgs))
+	for i, msg := range msgs {
+		list[i] = msg
+	}
+	c.Render(code, protoRender{opts: h.Codec.Marshal, msgs: list, list: true})
+}
+
+type protoRender struct {
+	opts protojson.MarshalOptions
+	msgs []proto.Message
+	list bool
+}
+
+func (r protoRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	if !r.list {
+		return r.write(w, r.msgs[0])
+	}
+
+	_, err := w.Write([]byte("["))
+	if err != nil {
+		return err
+	}
+	for i, msg := range r.msgs {
+		if i > 0 {
+			_, err = w.Write([]byte(","))
+			if err != nil {
+				return err
+			}
+		}
+		err = r.write(w, msg)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = w.Write([]byte("]"))
+	return err
+}
+
+func (r protoRender) write(w io.Writer, msg proto.Message) error {
+	data, err := r.opts.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (r protoRender) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+}
@@ -1,24 +1,57 @@
 package handler
 
 import (
+	"api-gateway/api/middleware"
 	"api-gateway/config"
+	"api-gateway/genproto/authentication"
 	"api-gateway/genproto/item"
 	"api-gateway/genproto/user"
 	"api-gateway/pkg"
+	"api-gateway/pkg/health"
 	"api-gateway/pkg/logger"
+	"api-gateway/pkg/resilience"
 	"log/slog"
+
+	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
 	UserClient user.UserServiceClient
 	ItemClient item.ItemServiceClient
+	AuthClient authentication.AuthenticationClient
+	Breakers   map[string]*resilience.Breaker
+	Health     *health.Checker
 	Logger     *slog.Logger
+	LogLevel   *slog.LevelVar
+	Codec      Codec
+	Timeouts   *middleware.Timeouts
 }
 
-func NewHandler(cfg *config.Config) *Handler {
+func NewHandler(cfg *config.Config, clients *pkg.Clients, lg *logger.Logger) (*Handler, error) {
+	timeouts, err := middleware.NewTimeouts(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Handler{
-		UserClient: pkg.NewUserClient(cfg),
-		ItemClient: pkg.NewItemClient(cfg),
-		Logger:     logger.NewLogger(),
+		UserClient: clients.User,
+		ItemClient: clients.Item,
+		AuthClient: clients.Auth,
+		Breakers:   clients.Breakers,
+		Health:     clients.Health,
+		Logger:     lg.Logger,
+		LogLevel:   lg.Level,
+		Codec:      NewCodec(cfg),
+		Timeouts:   timeouts,
+	}, nil
+}
+
+// requestLogger returns the logger tagged by the RequestID middleware,
+// falling back to the handler logger on routes without it.
+func (h *Handler) requestLogger(c *gin.Context) *slog.Logger {
+	l, ok := logger.FromContext(c)
+	if !ok {
+		return h.Logger
 	}
+	return l
 }
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"api-gateway/pkg/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz godoc
+// @Summary Liveness probe
+// @Description Reports that the gateway process is up
+// @Tags health
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readyz godoc
+// @Summary Readiness probe
+// @Description Checks the connection and gRPC health of every backend service
+// @Tags health
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *Handler) Readyz(c *gin.Context) {
+	report := h.Health.Check(c)
+
+	code := http.StatusOK
+	if report.Status != health.StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, report)
+}
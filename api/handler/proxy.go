@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"api-gateway/api/response"
+	"api-gateway/pkg/deadline"
+)
+
+// Message is the constraint satisfied by pointers to generated request types.
+type Message[T any] interface {
+	*T
+	proto.Message
+}
+
+// Call is the signature shared by all generated unary client methods.
+type Call[Req, Res any] func(ctx context.Context, req Req, opts ...grpc.CallOption) (Res, error)
+
+// validator is implemented by request messages that can check themselves,
+// e.g. the ones generated by protoc-gen-validate.
+type validator interface {
+	Validate() error
+}
+
+// Unary builds a gin handler that fills a new request with the binders,
+// calls the backend and writes the response as protobuf JSON, so exposing an RPC
+// takes a single route:
+//
+//	item.GET("/:item_id", handler.Unary(h, "GetItem", h.ItemClient.GetItem, handler.Path))
+func Unary[Req, Res any, PReq Message[Req], PRes Message[Res]](h *Handler, name string,
+	call Call[PReq, PRes], binders ...Binder) gin.HandlerFunc {
+	return UnaryFunc(h, name, call, func(c *gin.Context, res PRes) {
+		h.ProtoJSON(c, http.StatusOK, res)
+	}, binders...)
+}
+
+// UnaryFunc is Unary with a custom renderer for the backend response.
+func UnaryFunc[Req, Res any, PReq Message[Req], PRes Message[Res]](h *Handler, name string,
+	call Call[PReq, PRes], render func(c *gin.Context, res PRes), binders ...Binder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := PReq(new(Req))
+		h.serve(c, name, req, binders, func(ctx context.Context) (proto.Message, error) {
+			return call(ctx, req)
+		}, func(c *gin.Context, res proto.Message) {
+			render(c, res.(PRes))
+		})
+	}
+}
+
+// serve fills req with the binders, validates it, calls the backend
+// within the timeout of the method and renders the response.
+func (h *Handler) serve(c *gin.Context, name string, req proto.Message, binders []Binder,
+	call func(ctx context.Context) (proto.Message, error), render func(c *gin.Context, res proto.Message)) {
+	log := h.requestLogger(c)
+	log.Info(name + " method is starting")
+
+	for _, bind := range binders {
+		err := bind(c, req)
+		if err != nil {
+			log.Error("failed to bind request", "method", name, "error", err)
+			bindError(c, err)
+			return
+		}
+	}
+
+	if v, ok := req.(validator); ok {
+		err := v.Validate()
+		if err != nil {
+			log.Error("invalid request", "method", name, "error", err)
+			response.Error(c, codes.InvalidArgument, err.Error())
+			return
+		}
+	}
+
+	timeout, requested, err := h.Timeouts.For(c, name)
+	if err != nil {
+		log.Error("invalid request timeout", "method", name, "error", err)
+		response.Error(c, codes.InvalidArgument, err.Error())
+		return
+	}
+
+	// The remaining time travels to the backend as the gRPC deadline.
+	ctx, cancel := context.WithTimeout(deadline.WithCaller(c, requested), timeout)
+	defer cancel()
+
+	start := time.Now()
+	res, err := call(ctx)
+	if err != nil {
+		log.Error("backend call failed", "method", name,
+			"duration", time.Since(start), "timeout", timeout, "error", err)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			response.Error(c, codes.DeadlineExceeded,
+				fmt.Sprintf("%s did not complete within %s", name, timeout))
+			return
+		}
+		response.GRPCError(c, err, name+" request failed")
+		return
+	}
+
+	log.Debug("backend call finished", "method", name, "duration", time.Since(start))
+	render(c, res)
+}
+
+func bindError(c *gin.Context, err error) {
+	if _, ok := status.FromError(err); ok {
+		response.GRPCError(c, err, "invalid request")
+		return
+	}
+	response.Error(c, codes.InvalidArgument, errors.Wrap(err, "invalid data").Error())
+}
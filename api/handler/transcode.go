@@ -0,0 +1,409 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"api-gateway/api/response"
+	"api-gateway/pkg/transcode"
+)
+
+// SubjectFields names, per gRPC method, the request field that is set to
+// the caller's user ID, as the Subject binder does on the handwritten
+// routes.
+var SubjectFields = map[protoreflect.FullName]string{
+	"authentication.Authentication.Logout":     "user_id",
+	"item.ItemService.AddItem":                 "user_id",
+	"item.ItemService.DeleteItem":              "user_id",
+	"item.ItemService.SendSwapRequest":         "user_id",
+	"item.ItemService.AddRating":               "rater_id",
+	"item.ItemService.SubmitItemsForRecycling": "user_id",
+	"item.ItemService.ParticipateEcoChallenge": "user_id",
+}
+
+// Service is a backend service whose google.api.http bindings are served.
+// Bindings, when set, are served instead, and Desc is not needed.
+// UpdateMasks states that the backend applies update_mask; PATCH bindings
+// whose request has one are left out otherwise.
+type Service struct {
+	Conn        grpc.ClientConnInterface
+	Desc        protoreflect.ServiceDescriptor
+	Bindings    []transcode.Binding
+	UpdateMasks bool
+}
+
+// Route is a gin route serving one or more transcoded bindings.
+type Route struct {
+	Method   string
+	Path     string
+	RPCs     []protoreflect.FullName
+	Bindings []transcode.Binding
+	Handler  gin.HandlerFunc
+}
+
+// Transcode builds the routes declared with google.api.http on the
+// methods of the services, so an annotated RPC needs no handwritten
+// handler:
+//
+//	rpc GetItem(GetItemRequest) returns (ItemResponse) {
+//	  option (google.api.http) = { get: "/v1/items/{item_id}" };
+//	}
+//
+// Bindings that share a gin route, like custom methods on one resource
+// ("/v1/swaps/{swap_id}:accept" and ":reject"), are told apart by their
+// verb.
+func Transcode(h *Handler, services ...Service) ([]Route, error) {
+	var routes []Route
+	var targets [][]target
+	index := map[string]int{}
+
+	for _, svc := range services {
+		bindings := svc.Bindings
+		if bindings == nil {
+			var err error
+			bindings, err = transcode.Bindings(svc.Desc)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, b := range bindings {
+			if b.HTTPMethod == http.MethodPatch && updateMaskField(b.Method.Input()) != nil && !svc.UpdateMasks {
+				continue
+			}
+
+			path := b.Template.GinPath()
+			key := b.HTTPMethod + " " + path
+
+			i, ok := index[key]
+			if !ok {
+				i = len(routes)
+				index[key] = i
+				routes = append(routes, Route{Method: b.HTTPMethod, Path: path})
+				targets = append(targets, nil)
+			}
+
+			for _, t := range targets[i] {
+				if t.template.Verb == b.Template.Verb {
+					return nil, errors.Errorf("%s and %s are both bound to %s", t.rpc, b.Method.FullName(), key)
+				}
+			}
+
+			routes[i].RPCs = append(routes[i].RPCs, b.Method.FullName())
+			routes[i].Bindings = append(routes[i].Bindings, b)
+			targets[i] = append(targets[i], target{
+				rpc:      b.Method.FullName(),
+				template: b.Template,
+				handle:   h.transcoded(svc.Conn, b),
+			})
+		}
+	}
+
+	for i := range routes {
+		routes[i].Handler = dispatch(targets[i])
+	}
+
+	return routes, nil
+}
+
+type target struct {
+	rpc      protoreflect.FullName
+	template *transcode.Template
+	handle   gin.HandlerFunc
+}
+
+// dispatch picks the target whose verb the request ends in, falling back
+// to the one without a verb.
+func dispatch(targets []target) gin.HandlerFunc {
+	if len(targets) == 1 && targets[0].template.Verb == "" {
+		return targets[0].handle
+	}
+
+	return func(c *gin.Context) {
+		var fallback gin.HandlerFunc
+		for _, t := range targets {
+			if t.template.Verb == "" {
+				fallback = t.handle
+				continue
+			}
+			if t.template.MatchVerb(c.Param) {
+				t.handle(c)
+				return
+			}
+		}
+
+		if fallback == nil {
+			response.Error(c, codes.NotFound, "no method matches "+c.Request.URL.Path)
+			return
+		}
+		fallback(c)
+	}
+}
+
+// transcoded serves one binding. The request and response types are the
+// compiled ones when the binding was declared in the compiled protos,
+// dynamic ones for descriptors loaded at runtime.
+func (h *Handler) transcoded(conn grpc.ClientConnInterface, b transcode.Binding) gin.HandlerFunc {
+	md := b.Method
+	fullMethod := "/" + string(md.Parent().FullName()) + "/" + string(md.Name())
+	reqType := messageType(md.Input())
+	resType := messageType(md.Output())
+
+	var bound []string
+	for _, v := range b.Template.Variables {
+		bound = append(bound, v.FieldPath)
+	}
+
+	var binders []Binder
+	switch b.Body {
+	case "*":
+		binders = append(binders, h.Body)
+	case "":
+		binders = append(binders, queryExcept(bound))
+	default:
+		binders = append(binders, h.bodyField(b.Body), queryExcept(append(bound, b.Body)))
+	}
+	if fd := updateMaskField(md.Input()); fd != nil && b.HTTPMethod == http.MethodPatch && b.Body != "" {
+		// The mask is read from the body before it is decoded.
+		binders = append([]Binder{updateMask(fd, b.Body)}, binders...)
+	}
+	binders = append(binders, pathValues(b.Template))
+	if field, ok := SubjectFields[md.FullName()]; ok {
+		binders = append(binders, Subject(field))
+	}
+
+	render := func(c *gin.Context, res proto.Message) {
+		h.ProtoJSON(c, http.StatusOK, res)
+	}
+	if b.ResponseBody != "" {
+		render = h.renderField(protoreflect.Name(b.ResponseBody))
+	}
+
+	return func(c *gin.Context) {
+		req := reqType.New().Interface()
+		h.serve(c, string(md.Name()), req, binders, func(ctx context.Context) (proto.Message, error) {
+			res := resType.New().Interface()
+			err := conn.Invoke(ctx, fullMethod, req, res)
+			return res, err
+		}, render)
+	}
+}
+
+func messageType(md protoreflect.MessageDescriptor) protoreflect.MessageType {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName())
+	if err != nil || mt.Descriptor() != md {
+		return dynamicpb.NewMessageType(md)
+	}
+	return mt
+}
+
+// pathValues sets the fields bound by the path template.
+func pathValues(t *transcode.Template) Binder {
+	return func(c *gin.Context, msg proto.Message) error {
+		for field, value := range t.Values(c.Param) {
+			err := SetField(msg, field, value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// queryExcept is Query for the fields that are not bound by the path or
+// the body.
+func queryExcept(bound []string) Binder {
+	return func(c *gin.Context, msg proto.Message) error {
+		for key, values := range c.Request.URL.Query() {
+			if isBound(key, bound) {
+				continue
+			}
+			for _, value := range values {
+				err := SetField(msg, key, value)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func isBound(key string, bound []string) bool {
+	for _, b := range bound {
+		if key == b || strings.HasPrefix(key, b+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyField decodes the JSON request body into the named field.
+func (h *Handler) bodyField(name string) Binder {
+	return func(c *gin.Context, msg proto.Message) error {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return nil
+		}
+
+		fd := msg.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(name))
+		key, err := json.Marshal(fd.JSONName())
+		if err != nil {
+			return err
+		}
+
+		wrapped := []byte("{" + string(key) + ":" + string(data) + "}")
+
+		decoded := msg.ProtoReflect().New().Interface()
+		err = h.Codec.Unmarshal.Unmarshal(wrapped, decoded)
+		if err != nil {
+			return err
+		}
+
+		proto.Merge(msg, decoded)
+		return nil
+	}
+}
+
+// updateMaskField returns the update_mask field of md, if it has one of
+// type google.protobuf.FieldMask.
+func updateMaskField(md protoreflect.MessageDescriptor) protoreflect.FieldDescriptor {
+	fd := md.Fields().ByName("update_mask")
+	if fd == nil || fd.IsList() || fd.Message() == nil || fd.Message().FullName() != "google.protobuf.FieldMask" {
+		return nil
+	}
+	return fd
+}
+
+// updateMask makes PATCH a partial update: unless the client sent a mask,
+// the update_mask field lists the fields present in the JSON body, so
+// that the backend leaves the others alone. The body is left for the
+// binder that decodes it.
+func updateMask(maskField protoreflect.FieldDescriptor, body string) Binder {
+	return func(c *gin.Context, msg proto.Message) error {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+		if len(bytes.TrimSpace(data)) == 0 {
+			return errors.New("no fields to update")
+		}
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			// Not an object; decoding the body reports it.
+			return nil
+		}
+
+		m := msg.ProtoReflect()
+		md := m.Descriptor()
+		if body != "*" {
+			md = md.Fields().ByName(protoreflect.Name(body)).Message()
+			if md == nil {
+				return nil
+			}
+		} else {
+			for key := range fields {
+				if fieldByName(md, key) == maskField {
+					return nil
+				}
+			}
+		}
+
+		found := maskPaths(md, fields, "")
+		if len(found) == 0 {
+			return errors.New("no fields to update")
+		}
+
+		mask := m.NewField(maskField)
+		paths := mask.Message().Mutable(maskField.Message().Fields().ByName("paths")).List()
+		for _, path := range found {
+			paths.Append(protoreflect.ValueOfString(path))
+		}
+		m.Set(maskField, mask)
+		return nil
+	}
+}
+
+// maskPaths returns the field paths of a JSON object decoded into md,
+// descending into nested messages other than the well-known types.
+func maskPaths(md protoreflect.MessageDescriptor, fields map[string]json.RawMessage, prefix string) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var paths []string
+	for _, key := range keys {
+		fd := fieldByName(md, key)
+		if fd == nil {
+			continue
+		}
+		path := prefix + string(fd.Name())
+
+		var nested map[string]json.RawMessage
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() &&
+			fd.Message().FullName().Parent() != "google.protobuf" &&
+			json.Unmarshal(fields[key], &nested) == nil && nested != nil {
+			paths = append(paths, maskPaths(fd.Message(), nested, path+".")...)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// renderField renders one field of the response instead of all of it.
+func (h *Handler) renderField(name protoreflect.Name) func(c *gin.Context, res proto.Message) {
+	return func(c *gin.Context, res proto.Message) {
+		m := res.ProtoReflect()
+		fd := m.Descriptor().Fields().ByName(name)
+
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+			h.ProtoJSON(c, http.StatusOK, m.Get(fd).Message().Interface())
+			return
+		}
+
+		data, err := h.Codec.Marshal.Marshal(res)
+		if err != nil {
+			response.Error(c, codes.Internal, errors.Wrap(err, "failed to encode response").Error())
+			return
+		}
+
+		var fields map[string]json.RawMessage
+		err = json.Unmarshal(data, &fields)
+		if err != nil {
+			response.Error(c, codes.Internal, errors.Wrap(err, "failed to encode response").Error())
+			return
+		}
+
+		key := fd.JSONName()
+		if h.Codec.Marshal.UseProtoNames {
+			key = string(fd.Name())
+		}
+		value, ok := fields[key]
+		if !ok {
+			value = json.RawMessage("null")
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", value)
+	}
+}
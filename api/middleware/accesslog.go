@@ -0,0 +1,266 @@
+package middleware
+
+import (
+	"api-gateway/api/response"
+	"api-gateway/config"
+	"api-gateway/pkg/identity"
+	"api-gateway/pkg/logger"
+	"api-gateway/pkg/requestid"
+	"api-gateway/pkg/upstream"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	AccessLogJSON     = "json"
+	AccessLogCommon   = "common"
+	AccessLogCombined = "combined"
+
+	clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+)
+
+// AccessLog writes one record per request. Successful requests are
+// sampled at the configured rate; failed ones (status 400 and above) are
+// always logged.
+type AccessLog struct {
+	format   string
+	sampling atomic.Pointer[accessLogSampling]
+
+	mu     sync.Mutex
+	out    io.Writer
+	logger *slog.Logger
+}
+
+func NewAccessLog(cfg *config.Config, out io.Writer) (*AccessLog, error) {
+	a := AccessLog{
+		format: strings.ToLower(cfg.ACCESS_LOG_FORMAT),
+		out:    out,
+	}
+
+	switch a.format {
+	case AccessLogJSON:
+		a.logger = slog.New(logger.NewRedactHandler(slog.NewJSONHandler(out, nil)))
+	case AccessLogCommon, AccessLogCombined:
+	default:
+		return nil, errors.Errorf("unknown access log format %q", cfg.ACCESS_LOG_FORMAT)
+	}
+
+	err := a.Reload(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+type accessLogSampling struct {
+	enabled bool
+	rate    float64
+}
+
+// Reload applies the enabled flag and sample rate in cfg. The format and
+// output are fixed at startup.
+func (a *AccessLog) Reload(cfg *config.Config) error {
+	if cfg.ACCESS_LOG_SAMPLE_RATE < 0 || cfg.ACCESS_LOG_SAMPLE_RATE > 1 {
+		return errors.Errorf("access log sample rate %v is not between 0 and 1", cfg.ACCESS_LOG_SAMPLE_RATE)
+	}
+
+	a.sampling.Store(&accessLogSampling{
+		enabled: cfg.ACCESS_LOG_ENABLED,
+		rate:    cfg.ACCESS_LOG_SAMPLE_RATE,
+	})
+	return nil
+}
+
+// accessEntry is what is logged about a request.
+type accessEntry struct {
+	start     time.Time
+	latency   time.Duration
+	method    string
+	route     string
+	uri       string
+	proto     string
+	status    int
+	bytesIn   int64
+	bytesOut  int
+	clientIP  string
+	userID    string
+	requestID string
+	referer   string
+	userAgent string
+
+	grpcMethod string
+	grpcCode   codes.Code
+	hasCall    bool
+}
+
+// Handle must run after RequestID so that the request ID is known.
+func (a *AccessLog) Handle(c *gin.Context) {
+	sampling := a.sampling.Load()
+	if !sampling.enabled {
+		c.Next()
+		return
+	}
+
+	rec := &upstream.Recorder{}
+	c.Request = c.Request.WithContext(upstream.NewContext(c.Request.Context(), rec))
+
+	start := time.Now()
+	c.Next()
+
+	e := accessEntry{
+		start:     start,
+		latency:   time.Since(start),
+		method:    c.Request.Method,
+		route:     c.FullPath(),
+		uri:       logger.RedactURI(c.Request.URL),
+		proto:     c.Request.Proto,
+		status:    c.Writer.Status(),
+		bytesIn:   max(c.Request.ContentLength, 0),
+		bytesOut:  max(c.Writer.Size(), 0),
+		clientIP:  c.ClientIP(),
+		userID:    UserID(c),
+		requestID: c.GetString(response.RequestIDKey),
+		referer:   c.Request.Referer(),
+		userAgent: c.Request.UserAgent(),
+	}
+	if call, ok := rec.Last(); ok {
+		e.grpcMethod, e.grpcCode, e.hasCall = call.Method, call.Code, true
+	}
+
+	a.log(sampling, e)
+}
+
+// StreamServerInterceptor logs gRPC calls like Handle logs requests, with
+// the HTTP status matching the gRPC code. It must run after
+// RequestIDInterceptor.
+func (a *AccessLog) StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	sampling := a.sampling.Load()
+	if !sampling.enabled {
+		return handler(srv, ss)
+	}
+
+	// The caller is only known once the authenticator has run.
+	caller := &callerSlot{}
+	ss = withContext(ss, context.WithValue(ss.Context(), callerSlotKey{}, caller))
+
+	start := time.Now()
+	err := handler(srv, ss)
+	code := status.Code(err)
+
+	e := accessEntry{
+		start:      start,
+		latency:    time.Since(start),
+		method:     http.MethodPost,
+		route:      info.FullMethod,
+		uri:        info.FullMethod,
+		proto:      "HTTP/2.0",
+		status:     response.HTTPStatus(code),
+		clientIP:   peerIP(ss.Context()),
+		userID:     caller.id.UserID,
+		grpcMethod: info.FullMethod,
+		grpcCode:   code,
+		hasCall:    true,
+	}
+	e.requestID, _ = requestid.FromContext(ss.Context())
+	if md, ok := metadata.FromIncomingContext(ss.Context()); ok && len(md.Get("user-agent")) > 0 {
+		e.userAgent = md.Get("user-agent")[0]
+	}
+
+	a.log(sampling, e)
+	return err
+}
+
+// callerSlot receives the identity of a gRPC caller from the
+// authenticator, which runs after the access log.
+type callerSlot struct {
+	id identity.Identity
+}
+
+type callerSlotKey struct{}
+
+func (a *AccessLog) log(sampling *accessLogSampling, e accessEntry) {
+	if e.status < http.StatusBadRequest && rand.Float64() >= sampling.rate {
+		return
+	}
+
+	if a.logger != nil {
+		a.logJSON(e)
+		return
+	}
+	a.logLine(e)
+}
+
+func (a *AccessLog) logJSON(e accessEntry) {
+	attrs := []slog.Attr{
+		slog.String("method", e.method),
+		slog.String("route", e.route),
+		slog.String("path", e.uri),
+		slog.Int("status", e.status),
+		slog.Duration("latency", e.latency),
+		slog.Int64("bytes_in", e.bytesIn),
+		slog.Int("bytes_out", e.bytesOut),
+		slog.String("client_ip", e.clientIP),
+		slog.String("user_id", e.userID),
+		slog.String("request_id", e.requestID),
+	}
+	if e.hasCall {
+		attrs = append(attrs,
+			slog.String("grpc_method", e.grpcMethod),
+			slog.String("grpc_code", e.grpcCode.String()))
+	}
+
+	level := slog.LevelInfo
+	if e.status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	} else if e.status >= http.StatusBadRequest {
+		level = slog.LevelWarn
+	}
+
+	r := slog.NewRecord(e.start, level, "access", 0)
+	r.AddAttrs(attrs...)
+	a.logger.Handler().Handle(context.Background(), r)
+}
+
+// logLine writes the request in Common or Combined Log Format.
+func (a *AccessLog) logLine(e accessEntry) {
+	user := e.userID
+	if user == "" {
+		user = "-"
+	}
+
+	line := fmt.Sprintf("%s - %s [%s] %q %d %d",
+		e.clientIP,
+		user,
+		e.start.Format(clfTimeFormat),
+		logger.Redact(e.method+" "+e.uri+" "+e.proto),
+		e.status,
+		e.bytesOut,
+	)
+	if a.format == AccessLogCombined {
+		referer := e.referer
+		if referer == "" {
+			referer = "-"
+		}
+		line += fmt.Sprintf(" %q %q", logger.Redact(referer), e.userAgent)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	io.WriteString(a.out, line+"\n")
+}
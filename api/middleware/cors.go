@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"api-gateway/config"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORS answers preflight requests and adds the Access-Control headers for
+// the configured origins. Without origins it does nothing. The settings
+// can be replaced at runtime with Reload.
+type CORS struct {
+	settings atomic.Pointer[corsSettings]
+}
+
+type corsSettings struct {
+	allowAll    bool
+	origins     map[string]bool
+	methods     string
+	headers     string
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+func NewCORS(cfg *config.Config) *CORS {
+	c := CORS{}
+	c.Reload(cfg)
+	return &c
+}
+
+// Reload switches to the CORS settings in cfg.
+func (cors *CORS) Reload(cfg *config.Config) error {
+	s := corsSettings{
+		origins:     map[string]bool{},
+		methods:     strings.Join(cfg.CORS_ALLOWED_METHODS, ", "),
+		headers:     strings.Join(cfg.CORS_ALLOWED_HEADERS, ", "),
+		exposed:     strings.Join(cfg.CORS_EXPOSED_HEADERS, ", "),
+		credentials: cfg.CORS_ALLOW_CREDENTIALS,
+		maxAge:      strconv.Itoa(int(cfg.CORS_MAX_AGE.Seconds())),
+	}
+	for _, origin := range cfg.CORS_ALLOWED_ORIGINS {
+		if origin == "*" {
+			s.allowAll = true
+		}
+		s.origins[strings.ToLower(origin)] = true
+	}
+	cors.settings.Store(&s)
+	return nil
+}
+
+func (cors *CORS) Handle(c *gin.Context) {
+	if cors.apply(c.Writer.Header(), c.Request) {
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+	c.Next()
+}
+
+// Wrap adds the Access-Control headers to requests served outside gin,
+// such as gRPC-Web calls. Their preflight requests carry no gRPC content
+// type and are answered by Handle.
+func (cors *CORS) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cors.apply(w.Header(), r) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apply sets the Access-Control headers for r and reports whether it is a
+// preflight request, which needs no further handling.
+func (cors *CORS) apply(h http.Header, r *http.Request) bool {
+	s := cors.settings.Load()
+
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(s.origins) == 0 {
+		return false
+	}
+
+	h.Add("Vary", "Origin")
+	if !s.allowAll && !s.origins[strings.ToLower(origin)] {
+		return false
+	}
+
+	if s.allowAll && !s.credentials {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+	if s.credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+	if !preflight {
+		if s.exposed != "" {
+			h.Set("Access-Control-Expose-Headers", s.exposed)
+		}
+		return false
+	}
+
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+	h.Set("Access-Control-Allow-Methods", s.methods)
+	h.Set("Access-Control-Allow-Headers", s.headers)
+	h.Set("Access-Control-Max-Age", s.maxAge)
+	return true
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"api-gateway/config"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecation marks the routes it is used on as deprecated with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers. The routes are
+// deprecated since /v1 replaces them, so Deprecation is always sent: with
+// the date of the legacy settings, or as "true" like the drafts of RFC
+// 9745 did when no date is set. Sunset is left out without a date.
+func Deprecation(cfg *config.Config) gin.HandlerFunc {
+	deprecation, sunset := "true", ""
+	if t, err := time.Parse(config.DateLayout, cfg.LEGACY_DEPRECATED_AT); err == nil {
+		deprecation = "@" + strconv.FormatInt(t.Unix(), 10)
+	}
+	if t, err := time.Parse(config.DateLayout, cfg.LEGACY_SUNSET); err == nil {
+		sunset = t.Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if sunset != "" {
+			c.Header("Sunset", sunset)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"api-gateway/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDeprecationHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		deprecatedAt    string
+		sunset          string
+		wantDeprecation string
+		wantSunset      string
+	}{
+		{"no dates", "", "", "true", ""},
+		{"deprecation date", "2026-10-01", "", "@1790812800", ""},
+		{"sunset date", "2026-10-01", "2027-04-01", "@1790812800", "Thu, 01 Apr 2027 00:00:00 GMT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/legacy", Deprecation(&config.Config{
+				LEGACY_DEPRECATED_AT: tt.deprecatedAt,
+				LEGACY_SUNSET:        tt.sunset,
+			}), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/legacy", nil))
+
+			if got := w.Header().Get("Deprecation"); got != tt.wantDeprecation {
+				t.Errorf("Deprecation = %q, want %q", got, tt.wantDeprecation)
+			}
+			if got := w.Header().Get("Sunset"); got != tt.wantSunset {
+				t.Errorf("Sunset = %q, want %q", got, tt.wantSunset)
+			}
+		})
+	}
+}
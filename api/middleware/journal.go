@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"api-gateway/api/response"
+	"api-gateway/config"
+	"api-gateway/pkg/journal"
+	"api-gateway/pkg/logger"
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+// Journal records sanitized requests and responses as JSON lines, to be
+// replayed later with cmd/replay. Only allow-listed headers are kept, and
+// bodies are capped at the configured size.
+type Journal struct {
+	enabled bool
+	writer  *journal.Writer
+	headers []string
+	maxBody int
+	logger  *slog.Logger
+}
+
+func NewJournal(cfg *config.Config, out io.Writer, logger *slog.Logger) *Journal {
+	j := Journal{
+		enabled: cfg.JOURNAL_ENABLED && out != nil,
+		headers: cfg.JOURNAL_HEADERS,
+		maxBody: cfg.JOURNAL_MAX_BODY_BYTES,
+		logger:  logger,
+	}
+	if j.enabled {
+		j.writer = journal.NewWriter(out)
+	}
+	return &j
+}
+
+func (j *Journal) Handle(c *gin.Context) {
+	if !j.enabled {
+		c.Next()
+		return
+	}
+
+	var body []byte
+	truncated := false
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(c.Request.Body, int64(j.maxBody)+1))
+		if err != nil {
+			response.Error(c, codes.InvalidArgument, "failed to read request body")
+			return
+		}
+		c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+		if len(body) > j.maxBody {
+			body, truncated = nil, true
+		}
+	}
+
+	w := &journalWriter{ResponseWriter: c.Writer, max: j.maxBody}
+	c.Writer = w
+
+	start := time.Now()
+	c.Next()
+
+	e := journal.Entry{
+		Time:          start,
+		RequestID:     c.GetString(response.RequestIDKey),
+		Method:        c.Request.Method,
+		Route:         c.FullPath(),
+		Path:          logger.RedactURI(c.Request.URL),
+		Headers:       map[string]string{},
+		BodyTruncated: truncated,
+		Status:        w.Status(),
+		LatencyMS:     float64(time.Since(start).Microseconds()) / 1000,
+	}
+	e.Body, e.BodyRaw, e.BodyRedacted = journal.SanitizeRequest(body)
+	for _, name := range j.headers {
+		if value := c.Request.Header.Get(name); value != "" {
+			e.Headers[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	if w.truncated {
+		e.ResponseTruncated = true
+	} else {
+		e.Response = journal.Sanitize(w.body.Bytes())
+	}
+
+	err := j.writer.Write(e)
+	if err != nil {
+		j.logger.Error("failed to journal request", "error", err)
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// journalWriter keeps a copy of the first max bytes of the response.
+type journalWriter struct {
+	gin.ResponseWriter
+	max       int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *journalWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *journalWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *journalWriter) capture(b []byte) {
+	if w.truncated {
+		return
+	}
+	if w.body.Len()+len(b) > w.max {
+		w.truncated = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(b)
+}
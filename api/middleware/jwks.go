@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// minJWKSRefresh limits how often unknown kids may trigger a refetch, so
+// that forged tokens cannot be used to hammer the JWKS endpoint. Keys from
+// the last successful fetch stay in use while refetches fail.
+const minJWKSRefresh = time.Minute
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKS caches the public keys published at a JSON Web Key Set endpoint.
+// A background goroutine refetches them every interval, and early when an
+// unknown kid shows up, so that requests never wait for the endpoint.
+type JWKS struct {
+	url      string
+	interval time.Duration
+	cooldown time.Duration
+	client   *http.Client
+
+	wake     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	mu   sync.RWMutex
+	keys map[string]interface{}
+}
+
+// NewJWKS fetches the keys once and keeps them fresh until Close.
+func NewJWKS(url string, interval time.Duration) *JWKS {
+	return newJWKS(url, interval, minJWKSRefresh)
+}
+
+func newJWKS(url string, interval, cooldown time.Duration) *JWKS {
+	j := &JWKS{
+		url:      url,
+		interval: interval,
+		cooldown: cooldown,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		keys:     map[string]interface{}{},
+	}
+
+	j.refresh()
+	go j.run()
+
+	return j
+}
+
+// Key returns the key for kid from the last successful fetch. Unknown
+// kids fail right away and wake the background refresh, which picks up
+// keys the issuer has rotated in.
+func (j *JWKS) Key(kid string) (interface{}, error) {
+	j.mu.RLock()
+	key, ok := j.lookup(kid)
+	j.mu.RUnlock()
+
+	if !ok {
+		select {
+		case j.wake <- struct{}{}:
+		default:
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// Close stops the background refresh. Keys fetched so far stay usable.
+func (j *JWKS) Close() {
+	j.stopOnce.Do(func() {
+		close(j.stop)
+	})
+}
+
+func (j *JWKS) run() {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	attemptedAt := time.Now()
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+		case <-j.wake:
+			if time.Since(attemptedAt) < j.cooldown {
+				continue
+			}
+		}
+
+		attemptedAt = time.Now()
+		j.refresh()
+	}
+}
+
+func (j *JWKS) refresh() {
+	err := j.fetch()
+	if err != nil {
+		slog.Warn("failed to refresh JWKS", "url", j.url, "error", err)
+	}
+}
+
+func (j *JWKS) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+func (j *JWKS) fetch() error {
+	resp, err := j.client.Get(j.url)
+	if err != nil {
+		return errors.Wrap(err, "failed to request JWKS")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("JWKS endpoint returned %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&set)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode JWKS")
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			slog.Warn("skipping JWKS key", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+
+	return nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, errors.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base64url value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
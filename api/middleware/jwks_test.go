@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJWKSUnknownKidFailsFastAndRefreshesInBackground(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+	var rotated atomic.Bool
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys := []jwk{}
+		if rotated.Load() {
+			<-release
+			keys = append(keys, jwk{Kty: "EC", Kid: "new", Crv: "P-256",
+				X: encode(key.X.Bytes()), Y: encode(key.Y.Bytes())})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	jwks := newJWKS(server.URL, time.Hour, 0)
+	defer jwks.Close()
+
+	// The issuer rotates its key, and the endpoint is slow to answer.
+	rotated.Store(true)
+
+	start := time.Now()
+	_, err = jwks.Key("new")
+	if err == nil {
+		t.Fatal("Key() succeeded before the key was fetched")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Key() waited %s for the JWKS endpoint", elapsed)
+	}
+
+	release <- struct{}{}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err = jwks.Key("new")
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the background refresh did not pick up the rotated key: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
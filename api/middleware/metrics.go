@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"api-gateway/pkg/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that hit no route, so that scanners
+// probing random paths cannot blow up the number of series.
+const unmatchedRoute = "unmatched"
+
+// Metrics records count, latency and in-flight requests labelled by the
+// route template (e.g. /item-system/items/:item_id) rather than the raw path.
+func Metrics(c *gin.Context) {
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	method := c.Request.Method
+
+	inFlight := metrics.HTTPInFlight.WithLabelValues(route, method)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	start := time.Now()
+	c.Next()
+
+	status := strconv.Itoa(c.Writer.Status())
+	metrics.HTTPRequests.WithLabelValues(route, method, status).Inc()
+	metrics.HTTPDuration.WithLabelValues(route, method, status).Observe(time.Since(start).Seconds())
+}
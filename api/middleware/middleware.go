@@ -1,45 +1,295 @@
 package middleware
 
 import (
-	"net/http"
+	"api-gateway/api/response"
+	"api-gateway/config"
+	"api-gateway/pkg/identity"
+	"api-gateway/pkg/logger"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 const (
-	signingkey = "visca barsa"
+	ClaimsKey   = "claims"
+	IdentityKey = "identity"
 )
 
-func Check(c *gin.Context) {
-	accessToken := c.GetHeader("Authorization")
+// Authenticator verifies access tokens. Its keys and settings can be
+// replaced at runtime with Reload.
+type Authenticator struct {
+	verifier atomic.Pointer[verifier]
+}
 
-	if accessToken == "" {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"error": "Authorization is required",
-		})
-		return
+type verifier struct {
+	parser       *jwt.Parser
+	subjectClaim string
+	rolesClaim   string
+	secret       []byte
+	publicKey    interface{}
+	jwks         *JWKS
+}
+
+func NewAuthenticator(cfg *config.Config) (*Authenticator, error) {
+	a := Authenticator{}
+
+	err := a.Reload(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+// Reload switches to the JWT settings in cfg. Tokens are checked against
+// either the old or the new settings, never a mix of both.
+func (a *Authenticator) Reload(cfg *config.Config) (err error) {
+	v := verifier{
+		subjectClaim: cfg.JWT_SUBJECT_CLAIM,
+		rolesClaim:   cfg.JWT_ROLES_CLAIM,
+	}
+	defer func() {
+		if err != nil && v.jwks != nil {
+			v.jwks.Close()
+		}
+	}()
+
+	if cfg.JWT_SECRET != "" {
+		v.secret = []byte(cfg.JWT_SECRET)
+	}
+
+	if cfg.JWT_PUBLIC_KEY_FILE != "" {
+		key, err := loadPublicKey(cfg.JWT_PUBLIC_KEY_FILE)
+		if err != nil {
+			return err
+		}
+		v.publicKey = key
+	}
+
+	if cfg.JWT_JWKS_URL != "" {
+		v.jwks = NewJWKS(cfg.JWT_JWKS_URL, cfg.JWT_JWKS_REFRESH_INTERVAL)
+	}
+
+	if v.secret == nil && v.publicKey == nil && v.jwks == nil {
+		return errors.New("no JWT verification key configured")
+	}
+
+	if len(cfg.JWT_ALGORITHMS) == 0 {
+		return errors.New("no JWT signing algorithms allowed")
 	}
 
-	token, err := jwt.Parse(accessToken, func(t *jwt.Token) (interface{}, error) {
-		return []byte(signingkey), nil
-	})
+	for _, alg := range cfg.JWT_ALGORITHMS {
+		method := jwt.GetSigningMethod(alg)
+		if method == nil || alg == "none" {
+			return fmt.Errorf("unsupported JWT signing algorithm %q", alg)
+		}
+		if !v.hasKeyFor(method) {
+			return fmt.Errorf("no key configured for JWT signing algorithm %q", alg)
+		}
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(cfg.JWT_ALGORITHMS),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.JWT_CLOCK_SKEW),
+	}
+	if cfg.JWT_ISSUER != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.JWT_ISSUER))
+	}
+	if cfg.JWT_AUDIENCE != "" {
+		opts = append(opts, jwt.WithAudience(cfg.JWT_AUDIENCE))
+	}
+	v.parser = jwt.NewParser(opts...)
 
+	old := a.verifier.Swap(&v)
+	if old != nil && old.jwks != nil {
+		old.jwks.Close()
+	}
+	return nil
+}
+
+func (a *Authenticator) Check(c *gin.Context) {
+	id, claims, err := a.authenticate(c.GetHeader("Authorization"))
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"error": "Token could not be parsed",
-		})
+		response.Error(c, codes.Unauthenticated, err.Error())
 		return
 	}
-	
 
-	err = token.Claims.Valid()
+	c.Set(ClaimsKey, claims)
+	c.Set(IdentityKey, id)
+	c.Request = c.Request.WithContext(withIdentity(c.Request.Context(), id))
+
+	c.Next()
+}
+
+// StreamServerInterceptor checks the access token in the authorization
+// metadata of gRPC calls, except for the methods public reports.
+func (a *Authenticator) StreamServerInterceptor(public func(fullMethod string) bool) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if public(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		var header string
+		if md, ok := metadata.FromIncomingContext(ss.Context()); ok && len(md.Get("authorization")) > 0 {
+			header = md.Get("authorization")[0]
+		}
+
+		id, _, err := a.authenticate(header)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return handler(srv, withContext(ss, withIdentity(ss.Context(), id)))
+	}
+}
+
+// authenticate verifies the bearer token in an Authorization header. The
+// error is meant for the caller.
+func (a *Authenticator) authenticate(header string) (identity.Identity, jwt.MapClaims, error) {
+	if header == "" {
+		return identity.Identity{}, nil, errors.New("Authorization is required")
+	}
+
+	scheme, accessToken, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || accessToken == "" {
+		return identity.Identity{}, nil, errors.New("Authorization must use the Bearer scheme")
+	}
+
+	v := a.verifier.Load()
+
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(strings.TrimSpace(accessToken), claims, v.keyFunc)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid token provided",
+		return identity.Identity{}, nil, errors.New("Invalid token provided")
+	}
+
+	id, ok := v.identity(claims)
+	if !ok {
+		return identity.Identity{}, nil, errors.New("Token has no subject")
+	}
+
+	return id, claims, nil
+}
+
+// withIdentity stores the caller in ctx and adds the user ID to its
+// logger.
+func withIdentity(ctx context.Context, id identity.Identity) context.Context {
+	if slot, ok := ctx.Value(callerSlotKey{}).(*callerSlot); ok {
+		slot.id = id
+	}
+	ctx = identity.NewContext(ctx, id)
+	if l, ok := logger.FromContext(ctx); ok {
+		ctx = logger.NewContext(ctx, l.With("user_id", id.UserID))
+	}
+	return ctx
+}
+
+// UserID returns the subject of the verified access token, or an empty
+// string on routes that are not behind the authenticator.
+func UserID(c *gin.Context) string {
+	id, _ := Identity(c)
+	return id.UserID
+}
+
+func Identity(c *gin.Context) (identity.Identity, bool) {
+	id, ok := c.Get(IdentityKey)
+	if !ok {
+		return identity.Identity{}, false
+	}
+	return id.(identity.Identity), true
+}
+
+func (v *verifier) identity(claims jwt.MapClaims) (identity.Identity, bool) {
+	id := identity.Identity{}
+
+	switch sub := claims[v.subjectClaim].(type) {
+	case string:
+		id.UserID = sub
+	case float64:
+		id.UserID = fmt.Sprint(int64(sub))
+	}
+	if id.UserID == "" {
+		return id, false
+	}
+
+	switch roles := claims[v.rolesClaim].(type) {
+	case string:
+		id.Roles = strings.FieldsFunc(roles, func(r rune) bool {
+			return r == ',' || r == ' '
 		})
-		return
+	case []interface{}:
+		for _, role := range roles {
+			if role, ok := role.(string); ok {
+				id.Roles = append(id.Roles, role)
+			}
+		}
 	}
 
-	c.Next()
-}
\ No newline at end of file
+	return id, true
+}
+
+func (v *verifier) keyFunc(t *jwt.Token) (interface{}, error) {
+	switch t.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if v.secret == nil {
+			return nil, errors.New("no secret configured for HMAC tokens")
+		}
+		return v.secret, nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	if v.jwks != nil && (kid != "" || v.publicKey == nil) {
+		return v.jwks.Key(kid)
+	}
+
+	if v.publicKey == nil {
+		return nil, errors.New("no public key configured")
+	}
+	return v.publicKey, nil
+}
+
+func (v *verifier) hasKeyFor(method jwt.SigningMethod) bool {
+	switch method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.secret != nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := v.publicKey.(*rsa.PublicKey)
+		return ok || v.jwks != nil
+	case *jwt.SigningMethodECDSA:
+		_, ok := v.publicKey.(*ecdsa.PublicKey)
+		return ok || v.jwks != nil
+	}
+	return false
+}
+
+func loadPublicKey(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read JWT public key")
+	}
+
+	rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err == nil {
+		return rsaKey, nil
+	}
+
+	ecKey, err := jwt.ParseECPublicKeyFromPEM(data)
+	if err == nil {
+		return ecKey, nil
+	}
+
+	return nil, errors.Errorf("JWT public key %s is neither an RSA nor an ECDSA PEM key", path)
+}
@@ -0,0 +1,264 @@
+package middleware
+
+import (
+	"api-gateway/api/response"
+	"api-gateway/pkg/grpcproxy"
+	"api-gateway/pkg/identity"
+	"api-gateway/pkg/transcode"
+	_ "embed"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed policy.yaml
+var defaultPolicy []byte
+
+type Rule struct {
+	Method string   `yaml:"method"`
+	Path   string   `yaml:"path"`
+	Roles  []string `yaml:"roles"`
+	Owner  string   `yaml:"owner"`
+}
+
+type Policy struct {
+	// Default decides routes that no rule matches: "allow" lets any
+	// signed-in user through and "deny" refuses them.
+	Default string `yaml:"default"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+const (
+	PolicyAllow = "allow"
+	PolicyDeny  = "deny"
+)
+
+// LoadPolicy reads access rules from a YAML file, falling back to the
+// built-in policy when no path is given.
+func LoadPolicy(path string) (*Policy, error) {
+	data := defaultPolicy
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read policy file")
+		}
+	}
+
+	return ParsePolicy(data)
+}
+
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	err := yaml.Unmarshal(data, &p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse policy")
+	}
+
+	p.Default = strings.ToLower(p.Default)
+	switch p.Default {
+	case "":
+		p.Default = PolicyAllow
+	case PolicyAllow, PolicyDeny:
+	default:
+		return nil, errors.Errorf("unsupported policy default %q, want allow or deny", p.Default)
+	}
+
+	for i, rule := range p.Rules {
+		err := rule.validate()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid policy rule #%d (%s %s)", i+1, rule.Method, rule.Path)
+		}
+		p.Rules[i].Method = strings.ToUpper(rule.Method)
+	}
+
+	return &p, nil
+}
+
+func (r Rule) validate() error {
+	switch strings.ToUpper(r.Method) {
+	case "*", http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return errors.Errorf("unsupported method %q", r.Method)
+	}
+
+	if !strings.HasPrefix(r.Path, "/") {
+		return errors.New("path must start with /")
+	}
+
+	if r.Owner != "" && !IsGRPCMethod(r.Path) && !slices.Contains(strings.Split(r.Path, "/"), ":"+r.Owner) {
+		return errors.Errorf("owner parameter %q is not part of the path", r.Owner)
+	}
+
+	if len(r.Roles) == 0 && r.Owner == "" {
+		return errors.New("rule must require roles or an owner")
+	}
+
+	return nil
+}
+
+// IsGRPCMethod reports whether a rule path names a gRPC method, such as
+// /item.ItemService/AddItemCategory, rather than an HTTP route. The owner
+// of such a rule is a field of the request message.
+func IsGRPCMethod(path string) bool {
+	service, _, ok := transcode.ParseTarget(path)
+	return ok && strings.HasPrefix(path, "/") && strings.Contains(string(service), ".")
+}
+
+// Match returns the first rule for the route template and method.
+func (p *Policy) Match(method, path string) (Rule, bool) {
+	for _, rule := range p.Rules {
+		if rule.Path == path && (rule.Method == "*" || rule.Method == method) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// GRPCMethods returns the gRPC methods that rules apply to.
+func (p *Policy) GRPCMethods() []string {
+	var methods []string
+	for _, rule := range p.Rules {
+		if IsGRPCMethod(rule.Path) && (rule.Method == "*" || rule.Method == http.MethodPost) {
+			methods = append(methods, rule.Path)
+		}
+	}
+	return methods
+}
+
+// Allows reports whether the caller may use a route guarded by the rule.
+// params resolves path parameters for ownership checks.
+func (r Rule) Allows(userID string, roles []string, params func(string) string) bool {
+	for _, role := range r.Roles {
+		if role == "*" || slices.Contains(roles, role) {
+			return true
+		}
+	}
+
+	return r.Owner != "" && userID != "" && params(r.Owner) == userID
+}
+
+// AuthorizeInterceptor enforces the policy on gRPC calls, which match the
+// rules for POST and their method. When only the owner may call a method,
+// every request message is checked; methods whose request type the
+// gateway does not know are then refused.
+func AuthorizeInterceptor(p *Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		rule, ok := p.Match(http.MethodPost, info.FullMethod)
+		if !ok && p.Default == PolicyDeny {
+			return status.Error(codes.PermissionDenied, "Access denied")
+		}
+		if !ok {
+			return handler(srv, ss)
+		}
+
+		id, _ := identity.FromContext(ss.Context())
+		if rule.Allows(id.UserID, id.Roles, func(string) string { return "" }) {
+			return handler(srv, ss)
+		}
+		if rule.Owner == "" {
+			return status.Error(codes.PermissionDenied, "Access denied")
+		}
+
+		input, ok := requestType(info.FullMethod)
+		if !ok {
+			return status.Error(codes.PermissionDenied, "Access denied")
+		}
+
+		return handler(srv, &ownerStream{ServerStream: ss, rule: rule, id: id, input: input})
+	}
+}
+
+// ownerStream refuses request messages that belong to someone other than
+// the caller.
+type ownerStream struct {
+	grpc.ServerStream
+	rule  Rule
+	id    identity.Identity
+	input protoreflect.MessageDescriptor
+}
+
+func (s *ownerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err != nil {
+		return err
+	}
+
+	var msg proto.Message
+	switch m := m.(type) {
+	case *grpcproxy.Frame:
+		msg = dynamicpb.NewMessage(s.input)
+		err := proto.Unmarshal(m.Data, msg)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "invalid request message")
+		}
+	case proto.Message:
+		msg = m
+	default:
+		return status.Error(codes.PermissionDenied, "Access denied")
+	}
+
+	field := func(name string) string {
+		fd := msg.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+			return ""
+		}
+		return msg.ProtoReflect().Get(fd).String()
+	}
+	if !s.rule.Allows(s.id.UserID, s.id.Roles, field) {
+		return status.Error(codes.PermissionDenied, "Access denied")
+	}
+	return nil
+}
+
+// requestType looks up the request message of a compiled gRPC method.
+func requestType(fullMethod string) (protoreflect.MessageDescriptor, bool) {
+	service, name, ok := transcode.ParseTarget(fullMethod)
+	if !ok {
+		return nil, false
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(service)
+	if err != nil {
+		return nil, false
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok || sd.Methods().ByName(name) == nil {
+		return nil, false
+	}
+	return sd.Methods().ByName(name).Input(), true
+}
+
+// Authorize enforces the policy on routes behind the authenticator.
+func Authorize(p *Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rule, ok := p.Match(c.Request.Method, c.FullPath())
+		if !ok && p.Default == PolicyDeny {
+			response.Error(c, codes.PermissionDenied, "Access denied")
+			return
+		}
+		if !ok {
+			c.Next()
+			return
+		}
+
+		id, _ := Identity(c)
+		if !rule.Allows(id.UserID, id.Roles, c.Param) {
+			response.Error(c, codes.PermissionDenied, "Access denied")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,275 @@
+package middleware
+
+import (
+	"api-gateway/genproto/user"
+	"api-gateway/pkg/grpcproxy"
+	"api-gateway/pkg/identity"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const testPolicy = `
+rules:
+  - method: POST
+    path: /v1/categories
+    roles: [admin]
+  - method: PUT
+    path: /v1/users/:user_id
+    roles: [admin]
+    owner: user_id
+  - method: "*"
+    path: /admin/log-level
+    roles: [admin]
+  - method: POST
+    path: /user.UserService/DeleteUser
+    roles: [admin]
+  - method: POST
+    path: /user.UserService/UpdateUserProfile
+    roles: [admin]
+    owner: user_id
+`
+
+func parseTestPolicy(t *testing.T) *Policy {
+	t.Helper()
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDefaultPolicyParses(t *testing.T) {
+	_, err := LoadPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDefaultPolicyGuardsUserData checks that user lists and per-user
+// reads are limited to admins and the user, on every surface.
+func TestDefaultPolicyGuardsUserData(t *testing.T) {
+	p, err := LoadPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		owner  string
+	}{
+		{http.MethodPost, "/item-system/users", ""},
+		{http.MethodGet, "/item-system/users/:user_id", "user_id"},
+		{http.MethodGet, "/item-system/users/:user_id/eco-points", "user_id"},
+		{http.MethodPost, "/item-system/users/:user_id/eco-points/history", "user_id"},
+		{http.MethodGet, "/v1/users", ""},
+		{http.MethodGet, "/v1/users/:user_id", "user_id"},
+		{http.MethodGet, "/v1/users/:user_id/eco-points", "user_id"},
+		{http.MethodGet, "/v1/users/:user_id/eco-points/history", "user_id"},
+		{http.MethodPost, "/user.UserService/GetUsers", ""},
+		{http.MethodPost, "/user.UserService/GetUserProfile", "user_id"},
+		{http.MethodPost, "/user.UserService/GetEcoPoints", "user_id"},
+		{http.MethodPost, "/user.UserService/GetEcoPointsHistory", "user_id"},
+	}
+
+	for _, tt := range tests {
+		rule, ok := p.Match(tt.method, tt.path)
+		if !ok {
+			t.Errorf("%s %s has no rule", tt.method, tt.path)
+			continue
+		}
+		if !slices.Equal(rule.Roles, []string{"admin"}) || rule.Owner != tt.owner {
+			t.Errorf("%s %s = roles %v, owner %q; want roles [admin], owner %q", tt.method, tt.path, rule.Roles, rule.Owner, tt.owner)
+		}
+	}
+}
+
+func TestParsePolicyRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+	}{
+		{"unknown method", "{method: TRACE, path: /v1/items, roles: [admin]}"},
+		{"relative path", "{method: GET, path: v1/items, roles: [admin]}"},
+		{"owner not in path", "{method: PUT, path: /v1/items/:item_id, owner: user_id}"},
+		{"no roles or owner", "{method: GET, path: /v1/items}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePolicy([]byte("rules:\n  - " + tt.rule))
+			if err == nil {
+				t.Fatal("ParsePolicy() succeeded, want error")
+			}
+		})
+	}
+
+	_, err := ParsePolicy([]byte("default: block\nrules: []"))
+	if err == nil {
+		t.Error("ParsePolicy() accepted an unknown default")
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	admin := identity.Identity{UserID: "u1", Roles: []string{"admin"}}
+	alice := identity.Identity{UserID: "alice"}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		id     identity.Identity
+		want   int
+	}{
+		{"role matches", http.MethodPost, "/v1/categories", admin, http.StatusOK},
+		{"role missing", http.MethodPost, "/v1/categories", alice, http.StatusForbidden},
+		{"owner", http.MethodPut, "/v1/users/alice", alice, http.StatusOK},
+		{"someone else", http.MethodPut, "/v1/users/bob", alice, http.StatusForbidden},
+		{"role instead of owner", http.MethodPut, "/v1/users/bob", admin, http.StatusOK},
+		{"any method GET", http.MethodGet, "/admin/log-level", alice, http.StatusForbidden},
+		{"any method PUT", http.MethodPut, "/admin/log-level", alice, http.StatusForbidden},
+		{"any method as admin", http.MethodPut, "/admin/log-level", admin, http.StatusOK},
+		{"other method of guarded route", http.MethodGet, "/v1/categories", alice, http.StatusOK},
+		// Routes without a rule are open to any signed-in user.
+		{"unmatched route", http.MethodDelete, "/v1/items/1", alice, http.StatusOK},
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		for _, tt := range tests {
+			if tt.name == c.GetHeader("X-Test") {
+				c.Set(IdentityKey, tt.id)
+			}
+		}
+	}, Authorize(parseTestPolicy(t)))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.POST("/v1/categories", ok)
+	router.GET("/v1/categories", ok)
+	router.PUT("/v1/users/:user_id", ok)
+	router.GET("/admin/log-level", ok)
+	router.PUT("/admin/log-level", ok)
+	router.DELETE("/v1/items/:item_id", ok)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-Test", tt.name)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizeDeniesUnmatchedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	p, err := ParsePolicy([]byte(`
+default: deny
+rules:
+  - method: GET
+    path: /v1/items
+    roles: ["*"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(IdentityKey, identity.Identity{UserID: "alice"})
+	}, Authorize(p))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/v1/items", ok)
+	router.DELETE("/v1/items/:item_id", ok)
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/v1/items", http.StatusOK},
+		{http.MethodDelete, "/v1/items/1", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+		}
+	}
+}
+
+// recvStream is a server stream whose single request message is data.
+type recvStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	data []byte
+}
+
+func (s *recvStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *recvStream) RecvMsg(m interface{}) error {
+	m.(*grpcproxy.Frame).Data = s.data
+	return nil
+}
+
+func TestAuthorizeInterceptor(t *testing.T) {
+	admin := identity.Identity{UserID: "u1", Roles: []string{"admin"}}
+	alice := identity.Identity{UserID: "alice"}
+
+	tests := []struct {
+		name   string
+		method string
+		id     identity.Identity
+		req    proto.Message
+		want   codes.Code
+	}{
+		{"role matches", "/user.UserService/DeleteUser", admin,
+			&user.DeleteUserRequest{}, codes.OK},
+		{"role missing", "/user.UserService/DeleteUser", alice,
+			&user.DeleteUserRequest{}, codes.PermissionDenied},
+		{"owner field", "/user.UserService/UpdateUserProfile", alice,
+			&user.UpdateUserProfileRequest{UserId: "alice"}, codes.OK},
+		{"someone else", "/user.UserService/UpdateUserProfile", alice,
+			&user.UpdateUserProfileRequest{UserId: "bob"}, codes.PermissionDenied},
+		{"role instead of owner", "/user.UserService/UpdateUserProfile", admin,
+			&user.UpdateUserProfileRequest{UserId: "bob"}, codes.OK},
+		{"unmatched method", "/user.UserService/GetUsers", alice,
+			&user.GetUsersRequest{}, codes.OK},
+	}
+
+	interceptor := AuthorizeInterceptor(parseTestPolicy(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := proto.Marshal(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ss := &recvStream{ctx: identity.NewContext(context.Background(), tt.id), data: data}
+			info := &grpc.StreamServerInfo{FullMethod: tt.method}
+
+			err = interceptor(nil, ss, info, func(srv interface{}, ss grpc.ServerStream) error {
+				return ss.RecvMsg(&grpcproxy.Frame{})
+			})
+			if status.Code(err) != tt.want {
+				t.Errorf("%s = %v, want %s", tt.method, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"api-gateway/api/response"
+	"api-gateway/config"
+	"api-gateway/pkg/identity"
+	"api-gateway/pkg/ratelimit"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	APIKeyHeader      = "X-API-Key"
+	defaultLimitGroup = "default"
+)
+
+// RateLimiter applies the configured per-group limits. The limits can be
+// replaced at runtime with Reload.
+type RateLimiter struct {
+	limiter *ratelimit.Limiter
+	limits  atomic.Pointer[rateLimits]
+}
+
+type rateLimits struct {
+	enabled bool
+	groups  map[string]ratelimit.Limit
+	apiKeys map[[sha256.Size]byte]bool
+}
+
+func NewRateLimiter(cfg *config.Config, store ratelimit.Store, clock ratelimit.Clock) (*RateLimiter, error) {
+	r := RateLimiter{
+		limiter: ratelimit.NewLimiter(store, clock),
+	}
+
+	err := r.Reload(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// Reload switches to the limits in cfg. Buckets of the previous limits
+// are kept, so clients do not get a fresh burst.
+func (r *RateLimiter) Reload(cfg *config.Config) error {
+	limits := rateLimits{
+		enabled: cfg.RATE_LIMIT_ENABLED,
+		groups:  map[string]ratelimit.Limit{},
+		apiKeys: map[[sha256.Size]byte]bool{},
+	}
+
+	for _, key := range cfg.API_KEYS {
+		limits.apiKeys[sha256.Sum256([]byte(key))] = true
+	}
+
+	for group, value := range cfg.RATE_LIMITS {
+		limit, err := ratelimit.ParseLimit(value)
+		if err != nil {
+			return errors.Wrapf(err, "rate limit for %q", group)
+		}
+		limits.groups[group] = limit
+	}
+
+	r.limits.Store(&limits)
+	return nil
+}
+
+// Group limits the routes of a group with the limit configured under its
+// name. Groups without one get the default limit, if there is any, in
+// buckets of their own.
+func (r *RateLimiter) Group(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := clientKey(UserID(c), r.knownKey(c.GetHeader(APIKeyHeader)), c.ClientIP())
+		res, limited := r.take(c, name, key)
+		if !limited {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+
+		if !res.Allowed {
+			header.Set("Retry-After", ceilSeconds(res.RetryAfter))
+			response.Error(c, codes.ResourceExhausted, "Rate limit exceeded")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// StreamServerInterceptor limits gRPC calls like Group, with the group
+// that group returns for the method. The RateLimit headers are sent as
+// metadata.
+func (r *RateLimiter) StreamServerInterceptor(group func(fullMethod string) string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+
+		var apiKey string
+		if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(APIKeyHeader)) > 0 {
+			apiKey = md.Get(APIKeyHeader)[0]
+		}
+		id, _ := identity.FromContext(ctx)
+
+		res, limited := r.take(ctx, group(info.FullMethod), clientKey(id.UserID, r.knownKey(apiKey), peerIP(ctx)))
+		if !limited {
+			return handler(srv, ss)
+		}
+
+		md := metadata.Pairs(
+			"ratelimit-limit", strconv.Itoa(res.Limit),
+			"ratelimit-remaining", strconv.Itoa(res.Remaining),
+			"ratelimit-reset", ceilSeconds(res.Reset),
+		)
+		if !res.Allowed {
+			md.Set("retry-after", ceilSeconds(res.RetryAfter))
+			ss.SetHeader(md)
+			return status.Error(codes.ResourceExhausted, "Rate limit exceeded")
+		}
+		ss.SetHeader(md)
+
+		return handler(srv, ss)
+	}
+}
+
+// take counts a request of the client against the limit of the group. It
+// returns false when the group is not limited or the store failed, in
+// which case the request goes through.
+func (r *RateLimiter) take(ctx context.Context, name, client string) (ratelimit.Result, bool) {
+	limits := r.limits.Load()
+
+	limit, ok := limits.groups[name]
+	if !ok {
+		limit, ok = limits.groups[defaultLimitGroup]
+	}
+
+	if !limits.enabled || !ok {
+		return ratelimit.Result{}, false
+	}
+
+	res, err := r.limiter.Allow(ctx, name+":"+client, limit)
+	if err != nil {
+		slog.Error("rate limiter store failed", "group", name, "error", err)
+		return ratelimit.Result{}, false
+	}
+
+	return res, true
+}
+
+// knownKey returns key if it is one of the configured API keys and ""
+// otherwise, so that made-up keys do not each get a bucket of their own.
+func (r *RateLimiter) knownKey(key string) string {
+	if key == "" || !r.limits.Load().apiKeys[sha256.Sum256([]byte(key))] {
+		return ""
+	}
+	return key
+}
+
+// clientKey identifies the caller by user ID, then known API key, then IP.
+func clientKey(userID, apiKey, ip string) string {
+	if userID != "" {
+		return "user:" + userID
+	}
+
+	if apiKey != "" {
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+
+	return "ip:" + ip
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
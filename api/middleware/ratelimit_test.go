@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"api-gateway/config"
+	"api-gateway/pkg/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newLimitedRouter(t *testing.T, trustedProxies []string) (*gin.Engine, *fakeClock) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		RATE_LIMIT_ENABLED: true,
+		RATE_LIMITS:        map[string]string{"auth": "2/1m"},
+		API_KEYS:           []string{"known-key"},
+	}
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter, err := NewRateLimiter(cfg, ratelimit.NewMemoryStore(), clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	err = router.SetTrustedProxies(trustedProxies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.POST("/login", limiter.Group("auth"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router, clock
+}
+
+func login(router *gin.Engine, header http.Header) int {
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimitCannotBeBypassed(t *testing.T) {
+	tests := []struct {
+		name   string
+		header func(i int) http.Header
+	}{
+		{"no headers", func(int) http.Header { return http.Header{} }},
+		{"made-up API keys", func(i int) http.Header {
+			return http.Header{APIKeyHeader: {"key-" + strconv.Itoa(i)}}
+		}},
+		{"forged X-Forwarded-For", func(i int) http.Header {
+			return http.Header{"X-Forwarded-For": {"203.0.113." + strconv.Itoa(i)}}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := newLimitedRouter(t, nil)
+
+			limited := 0
+			for i := 0; i < 5; i++ {
+				if login(router, tt.header(i)) == http.StatusTooManyRequests {
+					limited++
+				}
+			}
+			if limited != 3 {
+				t.Errorf("%d of 5 requests were limited, want 3", limited)
+			}
+		})
+	}
+}
+
+func TestRateLimitByKnownAPIKey(t *testing.T) {
+	router, _ := newLimitedRouter(t, nil)
+
+	for i := 0; i < 2; i++ {
+		login(router, http.Header{})
+	}
+	if code := login(router, http.Header{}); code != http.StatusTooManyRequests {
+		t.Fatalf("third request by IP got %d, want 429", code)
+	}
+
+	keyed := http.Header{APIKeyHeader: {"known-key"}}
+	if code := login(router, keyed); code != http.StatusOK {
+		t.Errorf("request with a known API key got %d, want its own bucket", code)
+	}
+}
+
+func TestRateLimitRefills(t *testing.T) {
+	router, clock := newLimitedRouter(t, nil)
+
+	for i := 0; i < 2; i++ {
+		login(router, http.Header{})
+	}
+	if code := login(router, http.Header{}); code != http.StatusTooManyRequests {
+		t.Fatalf("third request got %d, want 429", code)
+	}
+
+	clock.now = clock.now.Add(30 * time.Second)
+	if code := login(router, http.Header{}); code != http.StatusOK {
+		t.Errorf("request after the refill got %d, want 200", code)
+	}
+}
+
+func TestRateLimitTrustedProxy(t *testing.T) {
+	router, _ := newLimitedRouter(t, []string{"192.0.2.1"})
+
+	for i := 0; i < 5; i++ {
+		header := http.Header{"X-Forwarded-For": {"203.0.113." + strconv.Itoa(i)}}
+		if code := login(router, header); code != http.StatusOK {
+			t.Errorf("client %d behind the trusted proxy got %d, want 200", i, code)
+		}
+	}
+}
+
+func TestRateLimitDefaultBucketPerGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		RATE_LIMIT_ENABLED: true,
+		RATE_LIMITS:        map[string]string{"default": "1/1m"},
+	}
+	limiter, err := NewRateLimiter(cfg, ratelimit.NewMemoryStore(), &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/items", limiter.Group("items"), ok)
+	router.GET("/users", limiter.Group("users"), ok)
+
+	get := func(path string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	if code := get("/items"); code != http.StatusOK {
+		t.Fatalf("first /items request got %d, want 200", code)
+	}
+	if code := get("/users"); code != http.StatusOK {
+		t.Errorf("first /users request got %d, want a bucket of its own", code)
+	}
+	if code := get("/items"); code != http.StatusTooManyRequests {
+		t.Errorf("second /items request got %d, want 429", code)
+	}
+}
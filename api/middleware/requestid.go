@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"api-gateway/api/response"
+	"api-gateway/pkg/logger"
+	"api-gateway/pkg/requestid"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// maxRequestIDLength bounds client-supplied IDs, which end up in every
+// log line and are forwarded to the backends.
+const maxRequestIDLength = 128
+
+// RequestID takes the X-Request-ID of the request, or generates one when
+// it is missing or malformed, and echoes it in the response. It also
+// stores a logger tagged with the request ID, route and trace ID in the
+// request context; Authenticator.Check adds the user ID to it.
+//
+// It must run after Tracing so that the trace ID is known.
+func RequestID(base *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(response.RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(response.RequestIDKey, id)
+		c.Header(response.RequestIDHeader, id)
+
+		attrs := []any{"request_id", id, "route", c.FullPath()}
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.HasTraceID() {
+			attrs = append(attrs, "trace_id", sc.TraceID().String())
+		}
+
+		ctx := requestid.NewContext(c.Request.Context(), id)
+		ctx = logger.NewContext(ctx, base.With(attrs...))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// RequestIDInterceptor is RequestID for gRPC calls: the ID comes from the
+// x-request-id metadata and is sent back as a header.
+func RequestIDInterceptor(base *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		var id string
+		if md, ok := metadata.FromIncomingContext(ss.Context()); ok && len(md.Get(requestid.Header)) > 0 {
+			id = md.Get(requestid.Header)[0]
+		}
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		ss.SetHeader(metadata.Pairs(requestid.Header, id))
+
+		attrs := []any{"request_id", id, "route", info.FullMethod}
+		if sc := trace.SpanContextFromContext(ss.Context()); sc.HasTraceID() {
+			attrs = append(attrs, "trace_id", sc.TraceID().String())
+		}
+
+		ctx := requestid.NewContext(ss.Context(), id)
+		ctx = logger.NewContext(ctx, base.With(attrs...))
+
+		return handler(srv, withContext(ss, ctx))
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
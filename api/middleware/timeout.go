@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"api-gateway/config"
+	"api-gateway/pkg/deadline"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// Timeouts decides how long a backend call may take. The settings can be
+// replaced at runtime with Reload.
+type Timeouts struct {
+	settings atomic.Pointer[timeoutSettings]
+}
+
+type timeoutSettings struct {
+	def       time.Duration
+	overrides map[string]time.Duration
+}
+
+func NewTimeouts(cfg *config.Config) (*Timeouts, error) {
+	t := Timeouts{}
+
+	err := t.Reload(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// Reload switches to the timeouts in cfg.
+func (t *Timeouts) Reload(cfg *config.Config) error {
+	s := timeoutSettings{
+		def:       cfg.TIMEOUT_DEFAULT,
+		overrides: map[string]time.Duration{},
+	}
+
+	for key, value := range cfg.TIMEOUTS {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return errors.Errorf("invalid timeout %q for %q", value, key)
+		}
+		s.overrides[key] = d
+	}
+
+	t.settings.Store(&s)
+	return nil
+}
+
+// For returns the timeout of the backend call made by method on the
+// current route. A timeout configured for the route, e.g.
+// "GET /item-system/items/:item_id", wins over one for the method, e.g.
+// "GetItem", which wins over the default. A shorter limit asked for by the
+// client in X-Request-Timeout or grpc-timeout is honoured, and reported as
+// such; an invalid one is an error.
+func (t *Timeouts) For(c *gin.Context, method string) (timeout time.Duration, requested bool, err error) {
+	s := t.settings.Load()
+
+	timeout, ok := s.overrides[c.Request.Method+" "+c.FullPath()]
+	if !ok {
+		timeout, ok = s.overrides[method]
+	}
+	if !ok {
+		timeout = s.def
+	}
+
+	limit, ok, err := deadline.FromHeader(c.Request.Header)
+	if err != nil {
+		return 0, false, err
+	}
+	if ok && limit < timeout {
+		return limit, true, nil
+	}
+
+	return timeout, false, nil
+}
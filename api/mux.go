@@ -0,0 +1,106 @@
+package api
+
+import (
+	"api-gateway/api/handler"
+	"api-gateway/api/middleware"
+	"api-gateway/config"
+	"api-gateway/pkg"
+	"api-gateway/pkg/grpcproxy"
+	"api-gateway/pkg/grpcweb"
+	"api-gateway/pkg/logger"
+	"api-gateway/pkg/transcode"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Mux serves gRPC and gRPC-Web calls with the gRPC proxy and everything
+// else with the HTTP API, so that both share one port.
+type Mux struct {
+	http http.Handler
+	grpc http.Handler
+	web  http.Handler
+}
+
+func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case m.web != nil && grpcweb.IsGRPCWeb(r):
+		m.web.ServeHTTP(w, r)
+	case m.grpc != nil && isGRPC(r):
+		m.grpc.ServeHTTP(w, r)
+	default:
+		m.http.ServeHTTP(w, r)
+	}
+}
+
+func isGRPC(r *http.Request) bool {
+	ct := r.Header.Get("Content-Type")
+	return r.ProtoMajor == 2 && (ct == "application/grpc" || strings.HasPrefix(ct, "application/grpc+"))
+}
+
+// grpcMiddleware is what the gRPC proxy shares with the HTTP API.
+type grpcMiddleware struct {
+	authn     *middleware.Authenticator
+	policy    *middleware.Policy
+	limiter   *middleware.RateLimiter
+	accessLog *middleware.AccessLog
+}
+
+// newGRPCServer returns a gRPC server that forwards every call to the
+// backend of its service. Calls are logged and rate limited per backend
+// like HTTP requests, and need an access token unless the method is one
+// of publicRPCs. Only the methods of routes and of policy rules are
+// served; the others, such as those the backends call among themselves,
+// stay internal.
+func newGRPCServer(cfg *config.Config, clients *pkg.Clients, lg *logger.Logger, routes []handler.Route, mw grpcMiddleware) *grpc.Server {
+	conns := map[string]grpc.ClientConnInterface{}
+	for service, backend := range cfg.SERVICE_BACKENDS {
+		conns[service] = clients.Conns[backend]
+	}
+	proxy := grpcproxy.New(conns)
+
+	public := func(fullMethod string) bool {
+		service, method, ok := transcode.ParseTarget(fullMethod)
+		return ok && publicRPCs[service.Append(method)]
+	}
+	group := func(fullMethod string) string {
+		return cfg.SERVICE_BACKENDS[grpcproxy.Service(fullMethod)]
+	}
+
+	published := map[string]bool{}
+	for _, route := range routes {
+		for _, rpc := range route.RPCs {
+			published["/"+string(rpc.Parent())+"/"+string(rpc.Name())] = true
+		}
+	}
+	for _, method := range mw.policy.GRPCMethods() {
+		published[method] = true
+	}
+
+	return grpc.NewServer(
+		grpc.ForceServerCodec(grpcproxy.Codec{}),
+		grpc.UnknownServiceHandler(proxy.Handler),
+		grpc.ChainStreamInterceptor(
+			middleware.RequestIDInterceptor(lg.Logger),
+			mw.accessLog.StreamServerInterceptor,
+			publishedOnly(published),
+			mw.authn.StreamServerInterceptor(public),
+			middleware.AuthorizeInterceptor(mw.policy),
+			mw.limiter.StreamServerInterceptor(group),
+		),
+	)
+}
+
+// publishedOnly refuses calls to methods outside of published as if they
+// did not exist.
+func publishedOnly(published map[string]bool) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !published[info.FullMethod] {
+			return status.Errorf(codes.Unimplemented, "unknown method %s", info.FullMethod)
+		}
+		return handler(srv, ss)
+	}
+}
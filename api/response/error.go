@@ -0,0 +1,126 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	RequestIDKey    = "request_id"
+	RequestIDHeader = "X-Request-ID"
+)
+
+// Envelope is the body of every error returned by the gateway.
+type Envelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code      string            `json:"code" example:"NOT_FOUND"`
+	Message   string            `json:"message" example:"item not found"`
+	Details   []json.RawMessage `json:"details,omitempty" swaggertype:"array,object"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+var httpStatus = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+var codeNames = map[codes.Code]string{
+	codes.OK:                 "OK",
+	codes.Canceled:           "CANCELLED",
+	codes.Unknown:            "UNKNOWN",
+	codes.InvalidArgument:    "INVALID_ARGUMENT",
+	codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+	codes.NotFound:           "NOT_FOUND",
+	codes.AlreadyExists:      "ALREADY_EXISTS",
+	codes.PermissionDenied:   "PERMISSION_DENIED",
+	codes.ResourceExhausted:  "RESOURCE_EXHAUSTED",
+	codes.FailedPrecondition: "FAILED_PRECONDITION",
+	codes.Aborted:            "ABORTED",
+	codes.OutOfRange:         "OUT_OF_RANGE",
+	codes.Unimplemented:      "UNIMPLEMENTED",
+	codes.Internal:           "INTERNAL",
+	codes.Unavailable:        "UNAVAILABLE",
+	codes.DataLoss:           "DATA_LOSS",
+	codes.Unauthenticated:    "UNAUTHENTICATED",
+}
+
+// HTTPStatus returns the HTTP status code matching a gRPC code.
+func HTTPStatus(code codes.Code) int {
+	s, ok := httpStatus[code]
+	if !ok {
+		return http.StatusInternalServerError
+	}
+	return s
+}
+
+// Error aborts the request with an error envelope for the gRPC code.
+func Error(c *gin.Context, code codes.Code, message string) {
+	abort(c, code, message, nil)
+}
+
+// GRPCError aborts the request with the HTTP equivalent of a backend
+// error. Messages of server-side failures are replaced with fallback so
+// that backend internals do not leak to clients.
+func GRPCError(c *gin.Context, err error, fallback string) {
+	st := status.Convert(err)
+
+	message := st.Message()
+	switch st.Code() {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+		message = fallback
+	}
+
+	var details []json.RawMessage
+	for _, detail := range st.Proto().GetDetails() {
+		b, err := protojson.Marshal(detail)
+		if err != nil {
+			continue
+		}
+		details = append(details, b)
+	}
+
+	abort(c, st.Code(), message, details)
+}
+
+func abort(c *gin.Context, code codes.Code, message string, details []json.RawMessage) {
+	name, ok := codeNames[code]
+	if !ok {
+		name = codeNames[codes.Unknown]
+	}
+
+	requestID := c.GetString(RequestIDKey)
+	if requestID == "" {
+		requestID = c.GetHeader(RequestIDHeader)
+	}
+
+	c.AbortWithStatusJSON(HTTPStatus(code), Envelope{Error: ErrorBody{
+		Code:      name,
+		Message:   message,
+		Details:   details,
+		RequestID: requestID,
+	}})
+}
@@ -2,94 +2,268 @@ package api
 
 import (
 	"api-gateway/api/handler"
-	// "api-gateway/api/middleware"
+	"api-gateway/api/middleware"
 	"api-gateway/config"
-
-	_ "api-gateway/api/docs"
+	"api-gateway/genproto/authentication"
+	"api-gateway/genproto/item"
+	"api-gateway/genproto/user"
+	"api-gateway/pkg"
+	"api-gateway/pkg/dynamic"
+	"api-gateway/pkg/grpcweb"
+	"api-gateway/pkg/logger"
+	"api-gateway/pkg/ratelimit"
+	"api-gateway/pkg/reload"
+	"api-gateway/pkg/transcode"
+	"context"
+	"log"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
+// publicRPCs can be called through /v1 without an access token.
+var publicRPCs = map[protoreflect.FullName]bool{
+	"authentication.Authentication.Register":          true,
+	"authentication.Authentication.Login":             true,
+	"authentication.Authentication.CheckRefreshToken": true,
+	"authentication.Authentication.ResetPassword":     true,
+}
+
 // @title User Item System
 // @version 1.0
 // @description API Gateway of User Item System
 // @host localhost:8080
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 // BasePath: /
-func NewRouter(cfg *config.Config) *gin.Engine {
-	router := gin.Default()
+//
+// The middlewares whose settings can change at runtime are registered with
+// the reloader. When gRPC is enabled, the returned Mux also serves gRPC
+// and gRPC-Web calls through the same middlewares.
+func NewRouter(cfg *config.Config, clients *pkg.Clients, lg *logger.Logger, reloader *reload.Reloader) *Mux {
+	router := gin.New()
+	router.ContextWithFallback = true
+
+	// Without trusted proxies the client IP, which rate limits fall back
+	// on, cannot be set with X-Forwarded-For.
+	err := router.SetTrustedProxies(cfg.HTTP_TRUSTED_PROXIES)
+	if err != nil {
+		log.Fatalf("error setting trusted proxies: %v", err)
+	}
+
+	h, err := handler.NewHandler(cfg, clients, lg)
+	if err != nil {
+		log.Fatalf("error creating handler: %v", err)
+	}
+
+	accessLog, err := middleware.NewAccessLog(cfg, lg.Access)
+	if err != nil {
+		log.Fatalf("error creating access log: %v", err)
+	}
+
+	cors := middleware.NewCORS(cfg)
+
+	// Only the API routes are journaled, not probes, metrics or docs.
+	journal := middleware.NewJournal(cfg, lg.Journal, h.Logger)
+
+	router.Use(
+		middleware.Metrics,
+		middleware.Tracing,
+		middleware.RequestID(h.Logger),
+		accessLog.Handle,
+		cors.Handle,
+		gin.Recovery(),
+	)
+
+	authn, err := middleware.NewAuthenticator(cfg)
+	if err != nil {
+		log.Fatalf("error creating authenticator: %v", err)
+	}
+
+	policy, err := middleware.LoadPolicy(cfg.POLICY_FILE)
+	if err != nil {
+		log.Fatalf("error loading access policy: %v", err)
+	}
+
+	limiter, err := middleware.NewRateLimiter(cfg, ratelimit.NewMemoryStore(), nil)
+	if err != nil {
+		log.Fatalf("error creating rate limiter: %v", err)
+	}
+	rl := limiter.Group
+
+	reloader.Register(h.Timeouts, authn, limiter, cors, accessLog)
+
+	masks := func(backend string) bool {
+		return slices.Contains(cfg.PATCH_BACKENDS, backend)
+	}
+	services := []handler.Service{
+		{Conn: clients.Conns["auth"], Desc: authentication.File_auth_proto.Services().ByName("Authentication"), UpdateMasks: masks("auth")},
+		{Conn: clients.Conns["user"], Desc: user.File_user_proto.Services().ByName("UserService"), UpdateMasks: masks("user")},
+		{Conn: clients.Conns["item"], Desc: item.File_Item_proto.Services().ByName("ItemService"), UpdateMasks: masks("item")},
+	}
+
+	dynamicRoutes, err := dynamic.Routes(context.Background(), cfg, clients.Conns)
+	if err != nil {
+		log.Fatalf("error loading dynamic routes: %v", err)
+	}
+	for _, route := range dynamicRoutes {
+		services = append(services, handler.Service{
+			Conn:        clients.Conns[route.Backend],
+			Bindings:    []transcode.Binding{route.Binding},
+			UpdateMasks: masks(route.Backend),
+		})
+	}
+
+	routes, err := handler.Transcode(h, services...)
+	if err != nil {
+		log.Fatalf("error reading HTTP bindings: %v", err)
+	}
+
+	// Routes generated from the google.api.http annotations of the protos
+	// and from dynamic.routes. Each resource, e.g. /v1/items, is its own
+	// rate limit group.
+	for _, route := range routes {
+		handlers := []gin.HandlerFunc{journal.Handle}
+		if !public(route) {
+			handlers = append(handlers, authn.Check, middleware.Authorize(policy))
+		}
+		handlers = append(handlers, rl(resource(route.Path)), route.Handler)
+		router.Handle(route.Method, route.Path, handlers...)
+	}
 
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	doc, err := swaggerDoc(routes, cfg.PROTOJSON_USE_PROTO_NAMES)
+	if err != nil {
+		log.Fatalf("error building the API documentation: %v", err)
+	}
+	router.GET("/swagger/*any", swaggerUI(doc))
+
+	// The routes below predate /v1 and are kept for existing clients,
+	// which are told when they go away.
+	api := router.Group("/item-system", journal.Handle, middleware.Deprecation(cfg))
+
+	router.GET("/healthz", h.Healthz)
+	router.GET("/readyz", h.Readyz)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	admin := router.Group("/admin", authn.Check, middleware.Authorize(policy))
+	{
+		admin.GET("/circuit-breakers", h.CircuitBreakers)
+		admin.GET("/log-level", h.GetLogLevel)
+		admin.PUT("/log-level", h.SetLogLevel)
+	}
 
-	api := router.Group("/item-system")
-	// api.Use(middleware.Check)
+	auth := api.Group("/auth", rl("auth"))
+	{
+		auth.POST("/register", handler.Unary(h, "Register", h.AuthClient.Register, h.Body))
+		auth.POST("/login", handler.Unary(h, "Login", h.AuthClient.Login, h.Body))
+		auth.POST("/refresh", handler.Unary(h, "RefreshToken", h.AuthClient.CheckRefreshToken, h.Body))
+		auth.POST("/reset-password", handler.Unary(h, "ResetPassword", h.AuthClient.ResetPassword, h.Body))
+		auth.POST("/logout", authn.Check, handler.Unary(h, "Logout", h.AuthClient.Logout, h.Body, handler.Subject("user_id")))
+	}
 
-	h := handler.NewHandler(cfg)
+	api = api.Group("", authn.Check, middleware.Authorize(policy))
 
-	u := api.Group("/users")
+	u := api.Group("/users", rl("users"))
 	{
-		u.GET("/:user_id", h.GetUserProfile)
-		u.PUT("/:user_id", h.UpdateUserProfile)
-		u.DELETE("/:user_id", h.DeleteUser)
-		u.POST("", h.GetUsers)
-		u.GET("/:user_id/eco-points", h.GetEcoPoints)
-		u.PUT("/:user_id/eco-points", h.AddEcoPoints)
-		u.POST("/:user_id/eco-points/history", h.GetEcoPointsHistory)
+		u.GET("/:user_id", handler.Unary(h, "GetUserProfile", h.UserClient.GetUserProfile, handler.Path))
+		u.PUT("/:user_id", handler.Unary(h, "UpdateUserProfile", h.UserClient.UpdateUserProfile, h.Body, handler.Path))
+		u.DELETE("/:user_id", handler.UnaryFunc(h, "DeleteUser", h.UserClient.DeleteUser, handler.UserDeleted, handler.Path))
+		u.POST("", handler.UnaryFunc(h, "GetUsers", h.UserClient.GetUsers, h.RenderUsers, h.Body))
+		u.GET("/:user_id/eco-points", handler.Unary(h, "GetEcoPoints", h.UserClient.GetEcoPoints, handler.Path))
+		u.PUT("/:user_id/eco-points", handler.Unary(h, "AddEcoPoints", h.UserClient.AddEcoPoints, h.Body, handler.Path))
+		u.POST("/:user_id/eco-points/history", handler.Unary(h, "GetEcoPointsHistory", h.UserClient.GetEcoPointsHistory, h.Body, handler.Path))
 	}
 
-	category := api.Group("/category")
+	category := api.Group("/category", rl("category"))
 	{
-		category.POST("/catogories", h.AddItemCategory)
+		category.POST("/catogories", handler.Unary(h, "AddItemCategory", h.ItemClient.AddItemCategory, h.Body))
+	}
 
+	item := api.Group("items", rl("items"))
+	{
+		item.POST("/addItem", handler.Unary(h, "AddItem", h.ItemClient.AddItem, h.Body, handler.Subject("user_id")))
+		item.PUT("/:item_id", handler.Unary(h, "UpdateItem", h.ItemClient.UpdateItem, h.Body, handler.Path))
+		item.DELETE("/:item_id", handler.Unary(h, "DeleteItem", h.ItemClient.DeleteItem, handler.Path, handler.Subject("user_id")))
+		item.POST("", handler.Unary(h, "ListItems", h.ItemClient.ListItems, h.Body))
+		item.GET("/:item_id", handler.Unary(h, "GetItem", h.ItemClient.GetItem, handler.Path))
+		item.POST("/search", handler.Unary(h, "SearchItems", h.ItemClient.SearchItems, h.Body))
 	}
-	
 
-	item := api.Group("items")
+	ecoChannels := api.Group("ecosystem", rl("ecosystem"))
 	{
-		item.POST("/addItem", h.AddItem)
-		item.PUT("/:item_id", h.UpdateItem)
-		item.DELETE("/:item_id", h.DeleteItem)
-		item.POST("", h.ListItems)
-		item.GET("/:item_id", h.GetItem)
-		item.POST("/search", h.SearchItems)
-	
+		ecoChannels.POST("eco-challenge", handler.Unary(h, "CreateEcoChallenge", h.ItemClient.CreateEcoChallenge, h.Body))
+		ecoChannels.POST("/participate", handler.Unary(h, "ParticipateEcoChallenge", h.ItemClient.ParticipateEcoChallenge, h.Body, handler.Subject("user_id")))
+		ecoChannels.PUT("update", handler.Unary(h, "UpdateEcoChallengeProgress", h.ItemClient.UpdateEcoChallengeProgress, h.Body))
 	}
 
-	ecoChannels := api.Group("ecosystem")
+	ecoTips := api.Group("eco-tips", rl("eco-tips"))
 	{
-		ecoChannels.POST("eco-challenge", h.CreateEcoChallenge)
-        ecoChannels.POST("/participate", h.ParticipateEcoChallenge)
-        ecoChannels.PUT("update", h.UpdateEcoChallengeProgress)
+		ecoTips.POST("", handler.Unary(h, "CreateEcoTip", h.ItemClient.CreateEcoTip, h.Body))
+		ecoTips.GET("", handler.Unary(h, "GetEcoTips", h.ItemClient.GetEcoTips, h.Body))
 	}
 
-	rating := api.Group("ratings")
+	rating := api.Group("ratings", rl("ratings"))
 	{
-		rating.POST("add", h.AddRating)
-		rating.POST("GetAll", h.GetRatings)
-	
+		rating.POST("add", handler.Unary(h, "AddRating", h.ItemClient.AddRating, h.Body, handler.Subject("rater_id")))
+		rating.POST("GetAll", handler.Unary(h, "GetRatings", h.ItemClient.GetRatings, h.Body))
 	}
 
-	recycling := api.Group("recyclings")
+	recycling := api.Group("recyclings", rl("recyclings"))
 	{
-		recycling.POST("", h.AddRecyclingCenter)
-		recycling.GET("search", h.SearchRecyclingCenters)
-		recycling.GET("", h.SubmitItemsForRecycling)
+		recycling.POST("", handler.Unary(h, "AddRecyclingCenter", h.ItemClient.AddRecyclingCenter, h.Body))
+		recycling.GET("search", handler.Unary(h, "SearchRecyclingCenters", h.ItemClient.SearchRecyclingCenters, h.Body))
+		recycling.GET("", handler.Unary(h, "SubmitItemsForRecycling", h.ItemClient.SubmitItemsForRecycling, h.Body, handler.Subject("user_id")))
 	}
 
-	statistics := api.Group("statistics")
+	statistics := api.Group("statistics", rl("statistics"))
 	{
-		statistics.POST("", h.Statistics)
+		statistics.POST("", handler.Unary(h, "Statistics", h.ItemClient.Statistics, h.Body))
 	}
-	
 
-	swap := api.Group("swaps")
+	swap := api.Group("swaps", rl("swaps"))
 	{
-		swap.POST("/", h.SendSwapRequest)
-		swap.PUT("/accept", h.AcceptSwapRequest)
-		swap.PUT("/:swap_id", h.ListSwapRequests)
-		swap.PUT("/reject", h.RejectSwapRequest)
+		swap.POST("/", handler.Unary(h, "SendSwapRequest", h.ItemClient.SendSwapRequest, h.Body, handler.Subject("user_id")))
+		swap.PUT("/accept", handler.Unary(h, "AcceptSwapRequest", h.ItemClient.AcceptSwapRequest, h.Body))
+		swap.PUT("/:swap_id", handler.Unary(h, "ListSwapRequests", h.ItemClient.ListSwapRequests, h.Body))
+		swap.PUT("/reject", handler.Unary(h, "RejectSwapRequest", h.ItemClient.RejectSwapRequest, h.Body))
 	}
 
-	return router
-}
\ No newline at end of file
+	mux := &Mux{http: router}
+	if cfg.GRPC_ENABLED {
+		grpcServer := newGRPCServer(cfg, clients, lg, routes, grpcMiddleware{
+			authn:     authn,
+			policy:    policy,
+			limiter:   limiter,
+			accessLog: accessLog,
+		})
+		mux.grpc = grpcServer
+		if cfg.GRPC_WEB_ENABLED {
+			mux.web = cors.Wrap(grpcweb.Handler(grpcServer))
+		}
+	}
+
+	return mux
+}
+
+func public(route handler.Route) bool {
+	for _, rpc := range route.RPCs {
+		if !publicRPCs[rpc] {
+			return false
+		}
+	}
+	return true
+}
+
+// resource returns the first segment after the version, e.g. "items" for
+// /v1/items/:item_id.
+func resource(path string) string {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	if len(parts) < 2 {
+		return parts[0]
+	}
+	return parts[1]
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"api-gateway/config"
+	"api-gateway/pkg"
+	"api-gateway/pkg/logger"
+	"api-gateway/pkg/reload"
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+type Server struct {
+	cfg     *config.Config
+	clients *pkg.Clients
+	http    *http.Server
+}
+
+func NewServer(cfg *config.Config, clients *pkg.Clients, lg *logger.Logger, reloader *reload.Reloader) *Server {
+	mux := NewRouter(cfg, clients, lg, reloader)
+
+	srv := &http.Server{
+		Addr:              cfg.HTTP_PORT,
+		Handler:           mux,
+		ReadTimeout:       cfg.HTTP_READ_TIMEOUT,
+		ReadHeaderTimeout: cfg.HTTP_READ_HEADER_TIMEOUT,
+		WriteTimeout:      cfg.HTTP_WRITE_TIMEOUT,
+		IdleTimeout:       cfg.HTTP_IDLE_TIMEOUT,
+	}
+
+	// gRPC needs HTTP/2, which clients speak in cleartext (h2c) here; TLS
+	// is terminated in front of the gateway. Configuring the http.Server
+	// lets Shutdown send GOAWAY on the h2c connections, which then refuse
+	// new streams and close once their calls are done.
+	if cfg.GRPC_ENABLED {
+		h2s := &http2.Server{}
+		err := http2.ConfigureServer(srv, h2s)
+		if err != nil {
+			log.Fatalf("error configuring HTTP/2: %v", err)
+		}
+		srv.Handler = h2c.NewHandler(mux, h2s)
+	}
+
+	return &Server{
+		cfg:     cfg,
+		clients: clients,
+		http:    srv,
+	}
+}
+
+// Run serves on the configured address until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen")
+	}
+	return s.Serve(ctx, lis)
+}
+
+// Serve serves on lis until ctx is cancelled, then shuts down gracefully:
+// readiness starts failing, and after the drain delay the server stops
+// accepting connections and waits for in-flight requests to finish.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	conns := &trackingListener{Listener: lis, open: map[*trackedConn]struct{}{}}
+	lis = conns
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.http.Serve(lis)
+	}()
+
+	select {
+	case err := <-errc:
+		return errors.Wrap(err, "server stopped")
+	case <-ctx.Done():
+	}
+
+	log.Println("shutting down, draining in-flight requests")
+	s.clients.Health.Drain()
+
+	timer := time.NewTimer(s.cfg.SHUTDOWN_DRAIN_DELAY)
+	select {
+	case err := <-errc:
+		timer.Stop()
+		return errors.Wrap(err, "server stopped")
+	case <-timer.C:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.SHUTDOWN_TIMEOUT)
+	defer cancel()
+
+	err := s.http.Shutdown(shutdownCtx)
+	if err != nil {
+		conns.closeAll()
+		return errors.Wrap(err, "failed to shut down gracefully")
+	}
+
+	// Shutdown does not wait for the connections that h2c took over.
+	err = conns.wait(shutdownCtx)
+	if err != nil {
+		return errors.Wrap(err, "gRPC calls did not finish in time")
+	}
+
+	return nil
+}
+
+// trackingListener keeps track of the connections it accepted until they
+// are closed, including those hijacked from the http.Server.
+type trackingListener struct {
+	net.Listener
+
+	mu   sync.Mutex
+	open map[*trackedConn]struct{}
+}
+
+func (l *trackingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &trackedConn{Conn: conn, l: l}
+	l.mu.Lock()
+	l.open[c] = struct{}{}
+	l.mu.Unlock()
+	return c, nil
+}
+
+// wait blocks until every connection is closed. When ctx ends first, the
+// remaining connections are closed.
+func (l *trackingListener) wait(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		l.mu.Lock()
+		open := len(l.open)
+		l.mu.Unlock()
+		if open == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			l.closeAll()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *trackingListener) closeAll() {
+	l.mu.Lock()
+	conns := make([]*trackedConn, 0, len(l.open))
+	for c := range l.open {
+		conns = append(conns, c)
+	}
+	l.mu.Unlock()
+
+	for _, c := range conns {
+		c.Close()
+	}
+}
+
+type trackedConn struct {
+	net.Conn
+	l    *trackingListener
+	once sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.l.mu.Lock()
+		delete(c.l.open, c)
+		c.l.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"api-gateway/config"
+	"api-gateway/genproto/authentication"
+	"api-gateway/genproto/user"
+	"api-gateway/pkg"
+	"api-gateway/pkg/logger"
+	"api-gateway/pkg/reload"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// slowAuth answers Login after delay and reports on started when a call
+// arrives.
+type slowAuth struct {
+	authentication.UnimplementedAuthenticationServer
+	delay   time.Duration
+	started chan struct{}
+}
+
+func (s *slowAuth) Login(ctx context.Context, req *authentication.LoginRequest) (*authentication.LoginResponse, error) {
+	s.started <- struct{}{}
+	select {
+	case <-time.After(s.delay):
+		return &authentication.LoginResponse{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestServerDrainsOnShutdownSignal(t *testing.T) {
+	const drainDelay = 200 * time.Millisecond
+
+	backendLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := &slowAuth{delay: time.Second, started: make(chan struct{}, 1)}
+	backend := grpc.NewServer()
+	authentication.RegisterAuthenticationServer(backend, auth)
+	go backend.Serve(backendLis)
+	defer backend.Stop()
+
+	gin.SetMode(gin.TestMode)
+	cfg, err := config.Load([]string{
+		"-auth.jwt.secret=test-secret",
+		"-backends.auth.address=" + backendLis.Addr().String(),
+		"-logging.output=stdout",
+		"-logging.level=error",
+		"-logging.access.enabled=false",
+		"-server.shutdown.drain_delay=" + drainDelay.String(),
+		"-server.shutdown.timeout=5s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg, err := logger.NewLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lg.Close()
+	clients, err := pkg.NewClients(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clients.Close()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- NewServer(cfg, clients, lg, reload.New(cfg, nil, slog.Default())).Serve(ctx, lis)
+	}()
+
+	slow := make(chan int, 1)
+	go func() {
+		client := &http.Client{Transport: &http.Transport{}}
+		res, err := client.Post("http://"+addr+"/item-system/auth/login", "application/json",
+			strings.NewReader(`{"username":"alice","password":"secret"}`))
+		if err != nil {
+			t.Error(err)
+			slow <- 0
+			return
+		}
+		res.Body.Close()
+		slow <- res.StatusCode
+	}()
+
+	select {
+	case <-auth.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request did not reach the backend")
+	}
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signalled := time.Now()
+	err = process.Signal(syscall.SIGTERM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// New connections are accepted while load balancers notice the failing
+	// readiness, and refused once the drain delay is over.
+	for {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			if elapsed := time.Since(signalled); elapsed < drainDelay {
+				t.Errorf("connections refused after %s, before the drain delay of %s", elapsed, drainDelay)
+			}
+			break
+		}
+		conn.Close()
+		if time.Since(signalled) > 5*time.Second {
+			t.Fatal("connections are still accepted after shutting down")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case code := <-slow:
+		if code != http.StatusOK {
+			t.Errorf("in-flight request = %d, want %d", code, http.StatusOK)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the in-flight request did not complete")
+	}
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Serve() = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return")
+	}
+}
+
+func TestGRPCProxyDrainsOnShutdown(t *testing.T) {
+	const drainDelay = 100 * time.Millisecond
+
+	backendLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := &slowAuth{delay: 500 * time.Millisecond, started: make(chan struct{}, 2)}
+	backend := grpc.NewServer()
+	authentication.RegisterAuthenticationServer(backend, auth)
+	go backend.Serve(backendLis)
+	defer backend.Stop()
+
+	gin.SetMode(gin.TestMode)
+	cfg, err := config.Load([]string{
+		"-auth.jwt.secret=test-secret",
+		"-backends.auth.address=" + backendLis.Addr().String(),
+		"-grpc.enabled=true",
+		"-logging.output=stdout",
+		"-logging.level=error",
+		"-logging.access.enabled=false",
+		"-server.shutdown.drain_delay=" + drainDelay.String(),
+		"-server.shutdown.timeout=5s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg, err := logger.NewLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lg.Close()
+	clients, err := pkg.NewClients(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clients.Close()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- NewServer(cfg, clients, lg, reload.New(cfg, nil, slog.Default())).Serve(ctx, lis)
+	}()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := authentication.NewAuthenticationClient(conn)
+
+	// Methods without HTTP bindings or policy rules are internal.
+	_, err = user.NewUserServiceClient(conn).ValidateUserId(context.Background(), &user.ValidateUserIdRequest{Id: "42"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("ValidateUserId() = %v, want %s", err, codes.Unimplemented)
+	}
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := client.Login(context.Background(), &authentication.LoginRequest{Username: "alice", Password: "secret"})
+		slow <- err
+	}()
+
+	select {
+	case <-auth.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the call did not reach the backend")
+	}
+
+	shutdown()
+	time.Sleep(2 * drainDelay)
+
+	// The connection takes no new calls once draining starts.
+	callCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = client.Login(callCtx, &authentication.LoginRequest{Username: "bob", Password: "secret"})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("Login() after shutdown = %v, want %s", err, codes.Unavailable)
+	}
+
+	select {
+	case err := <-slow:
+		if err != nil {
+			t.Errorf("in-flight call = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the in-flight call did not complete")
+	}
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Serve() = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return")
+	}
+}
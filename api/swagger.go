@@ -0,0 +1,554 @@
+package api
+
+import (
+	"api-gateway/api/docs"
+	"api-gateway/api/handler"
+	"api-gateway/pkg/openapi"
+	"api-gateway/pkg/transcode"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// swaggerDoc returns the swag document of the handwritten routes with the
+// transcoded ones added, which are described from the google.api.http
+// annotations they are served from.
+func swaggerDoc(routes []handler.Route, useProtoNames bool) ([]byte, error) {
+	var doc map[string]interface{}
+	err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read swagger document")
+	}
+
+	var bindings []transcode.Binding
+	for _, route := range routes {
+		bindings = append(bindings, route.Bindings...)
+	}
+	generated := openapi.Build(bindings, openapi.Options{
+		UseProtoNames: useProtoNames,
+		Public:        func(rpc protoreflect.FullName) bool { return publicRPCs[rpc] },
+		Security:      "BearerAuth",
+		Error:         "response.Envelope",
+	})
+
+	// The generated definitions replace the ones swag derived from the Go
+	// types of the same messages, as they follow the protojson encoding.
+	for _, section := range []struct {
+		key   string
+		value interface{}
+	}{{"paths", generated.Paths}, {"definitions", generated.Definitions}} {
+		data, err := json.Marshal(section.value)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode swagger document")
+		}
+		var entries map[string]interface{}
+		err = json.Unmarshal(data, &entries)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode swagger document")
+		}
+
+		merged, _ := doc[section.key].(map[string]interface{})
+		if merged == nil {
+			merged = map[string]interface{}{}
+		}
+		for key, value := range entries {
+			merged[key] = value
+		}
+		doc[section.key] = merged
+	}
+
+	return json.Marshal(doc)
+}
+
+// swaggerUI serves the Swagger UI for doc.
+func swaggerUI(doc []byte) gin.HandlerFunc {
+	ui := ginSwagger.WrapHandler(swaggerFiles.Handler)
+	return func(c *gin.Context) {
+		if c.Param("any") == "/doc.json" {
+			c.Data(http.StatusOK, "application/json; charset=utf-8", doc)
+			return
+		}
+		ui(c)
+	}
+}
+
+// Swagger annotations of the /item-system routes registered in NewRouter.
+// swag only reads them from function declarations, hence the blank
+// functions.
+
+// Register godoc
+// @Summary Registers a new user
+// @Description Creates a new user account in the authentication service
+// @Tags auth
+// @Param user body authentication.UserDetails true "User details"
+// @Success 200 {object} authentication.ID
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while registering user"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Deprecated
+// @Router /item-system/auth/register [post]
+func _() {}
+
+// Login godoc
+// @Summary Logs a user in
+// @Description Checks user credentials and returns access and refresh tokens
+// @Tags auth
+// @Param credentials body authentication.LoginRequest true "Login credentials"
+// @Success 200 {object} authentication.LoginResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while logging in"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Deprecated
+// @Router /item-system/auth/login [post]
+func _() {}
+
+// RefreshToken godoc
+// @Summary Refreshes an access token
+// @Description Checks the refresh token and issues a new access token
+// @Tags auth
+// @Param token body authentication.CheckRefreshTokenRequest true "Refresh token"
+// @Success 200 {object} authentication.CheckRefreshTokenResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while refreshing token"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Deprecated
+// @Router /item-system/auth/refresh [post]
+func _() {}
+
+// Logout godoc
+// @Summary Logs a user out
+// @Description Revokes the refresh token of the user
+// @Tags auth
+// @Param user body authentication.LogoutRequest true "User info"
+// @Success 200 {object} authentication.LogoutResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while logging out"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/auth/logout [post]
+func _() {}
+
+// ResetPassword godoc
+// @Summary Resets user password
+// @Description Sends a password reset message to the user email
+// @Tags auth
+// @Param email body authentication.ResetPasswordRequest true "User email"
+// @Success 200 {object} authentication.ResetPasswordResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while resetting password"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Deprecated
+// @Router /item-system/auth/reset-password [post]
+func _() {}
+
+// GetUserProfile godoc
+// @Summary Gets user profile
+// @Description Retrieves user profile info from PostgreSQL
+// @Tags user
+// @Param user_id path string true "User ID"
+// @Success 200 {object} user.GetUserProfileResponse
+// @Failure 400 {object} response.Envelope "Invalid user ID"
+// @Failure 500 {object} response.Envelope "Server error while getting user profile"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/users/{user_id} [get]
+func _() {}
+
+// UpdateUserProfile godoc
+// @Summary Updates user profile
+// @Description Updates user profile info in PostgreSQL
+// @Tags user
+// @Param user_id path string true "User ID"
+// @Param new_info body user.UpdateUserProfileRequest true "Update user info"
+// @Success 200 {object} user.UpdateProfileResponse
+// @Failure 400 {object} response.Envelope "Invalid user ID or data"
+// @Failure 500 {object} response.Envelope "Server error while updating user profile"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/users/{user_id} [put]
+func _() {}
+
+// DeleteUser godoc
+// @Summary Deletes a user
+// @Description Removes user info from PostgreSQL
+// @Tags user
+// @Param user_id path string true "User ID"
+// @Success 200 {object} string "User deleted successfully"
+// @Failure 400 {object} response.Envelope "Invalid user ID"
+// @Failure 500 {object} response.Envelope "Server error while deleting user"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/users/{user_id} [delete]
+func _() {}
+
+// GetUsers godoc
+// @Summary Gets list of users
+// @Description Retrieves list of users from PostgreSQL
+// @Tags user
+// @Param new_info body user.GetUsersRequest true "filter user info"
+// @Success 200 {object} []user.GetUsersResponse
+// @Failure 500 {object} response.Envelope "Server error while getting users"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/users [post]
+func _() {}
+
+// GetEcoPoints godoc
+// @Summary Gets eco points of a user
+// @Description Retrieves eco points info from PostgreSQL
+// @Tags user
+// @Param user_id path string true "User ID"
+// @Success 200 {object} user.GetEcoPointsResponse
+// @Failure 400 {object} response.Envelope "Invalid user ID"
+// @Failure 500 {object} response.Envelope "Server error while getting eco points"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/users/{user_id}/eco-points [get]
+func _() {}
+
+// AddEcoPoints godoc
+// @Summary Adds eco points to a user
+// @Description Inserts eco points info into PostgreSQL
+// @Tags user
+// @Param user_id path string true "User ID"
+// @Param points body user.AddEcoPointsRequest true "Eco points info"
+// @Success 200 {object} user.AddEcoPointsResponse
+// @Failure 400 {object} response.Envelope "Invalid user ID or data"
+// @Failure 500 {object} response.Envelope "Server error while adding eco points"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/users/{user_id}/eco-points [put]
+func _() {}
+
+// GetEcoPointsHistory godoc
+// @Summary Gets eco points history of a user
+// @Description Retrieves eco points history from PostgreSQL
+// @Tags user
+// @Param user_id path string true "User ID"
+// @Param points body user.GetEcoPointsHistoryRequest true "Eco points history info"
+// @Success 200 {object} []user.GetEcoPointsHistoryResponse
+// @Failure 400 {object} response.Envelope "Invalid user ID"
+// @Failure 500 {object} response.Envelope "Server error while getting eco points history"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/users/{user_id}/eco-points/history [post]
+func _() {}
+
+// AddItemCategory godoc
+// @Summary Adds an item category
+// @Description Inserts new item category info into item_categories table in PostgreSQL
+// @Tags item
+// @Param new_data body item.AddItemCategoryRequest true "New data"
+// @Success 200 {object} item.AddItemCategoryResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while adding item category"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/category/catogories [post]
+func _() {}
+
+// AddItem godoc
+// @Summary Adds a new item
+// @Description Inserts new item info into items table in PostgreSQL
+// @Tags item
+// @Param new_data body item.AddItemRequest true "New item data"
+// @Success 200 {object} item.ItemResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while adding item"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/items/addItem [post]
+func _() {}
+
+// UpdateItem godoc
+// @Summary Updates an item
+// @Description Updates item info in items table in PostgreSQL
+// @Tags item
+// @Param item_id path string true "Item ID"
+// @Param update_data body item.UpdateItemRequest true "Updated item data"
+// @Success 200 {object} item.ItemResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while updating item"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/items/{item_id} [put]
+func _() {}
+
+// DeleteItem godoc
+// @Summary Deletes an item
+// @Description Deletes item from items table in PostgreSQL
+// @Tags item
+// @Param item_id path string true "Item ID"
+// @Success 200 {object} item.DeleteItemResponse
+// @Failure 400 {object} response.Envelope "Invalid item ID"
+// @Failure 500 {object} response.Envelope "Server error while deleting item"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/items/{item_id} [delete]
+func _() {}
+
+// ListItems godoc
+// @Summary Lists all items
+// @Description Retrieves all items info from items table in PostgreSQL
+// @Tags item
+// @Param update_data body item.ListItemsRequest true "list item data"
+// @Success 200 {object} item.ListItemsResponse
+// @Failure 500 {object} response.Envelope "Server error while listing items"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/items [post]
+func _() {}
+
+// GetItem godoc
+// @Summary Gets an item
+// @Description Retrieves item info from items table in PostgreSQL
+// @Tags item
+// @Param item_id path string true "Item ID"
+// @Success 200 {object} item.ItemResponse
+// @Failure 400 {object} response.Envelope "Invalid item ID"
+// @Failure 500 {object} response.Envelope "Server error while getting item"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/items/{item_id} [get]
+func _() {}
+
+// SearchItems godoc
+// @Summary Searches for items
+// @Description Searches items info in items table in PostgreSQL
+// @Tags item
+// @Param update_data body item.SearchItemsRequest true "list item data"
+// @Success 200 {object} item.ListItemsResponse
+// @Failure 500 {object} response.Envelope "Server error while searching items"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/items/search [post]
+func _() {}
+
+// CreateEcoChallenge godoc
+// @Summary Creates a new eco challenge
+// @Description Inserts new eco challenge info into eco_challenges table in PostgreSQL
+// @Tags eco_challenge
+// @Param new_data body item.CreateEcoChallengeRequest true "New data"
+// @Success 200 {object} item.CreateEcoChallengeResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while creating eco challenge"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/ecosystem/eco-challenge [post]
+func _() {}
+
+// ParticipateEcoChallenge godoc
+// @Summary Participates in an eco challenge
+// @Description Inserts new participation info into challenge_participations table in PostgreSQL
+// @Tags eco_challenge
+// @Param new_data body item.ParticipateEcoChallengeRequest true "New data"
+// @Success 200 {object} item.ParticipateEcoChallengeResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while participating in eco challenge"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/ecosystem/participate [post]
+func _() {}
+
+// UpdateEcoChallengeProgress godoc
+// @Summary Updates progress in an eco challenge
+// @Description Updates progress info in challenge_participations table in PostgreSQL
+// @Tags eco_challenge
+// @Param new_data body item.UpdateEcoChallengeProgressRequest true "New data"
+// @Success 200 {object} item.UpdateEcoChallengeProgressResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while updating eco challenge progress"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/ecosystem/update [put]
+func _() {}
+
+// CreateEcoTip godoc
+// @Summary Creates a new eco tip
+// @Description Inserts new eco tip info into eco_tips table in PostgreSQL
+// @Tags eco_tip
+// @Param new_data body item.CreateEcoTipRequest true "New data"
+// @Success 200 {object} item.CreateEcoTipResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while creating eco tip"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/eco-tips [post]
+func _() {}
+
+// GetEcoTips godoc
+// @Summary Gets all eco tips
+// @Description Retrieves all eco tips info from PostgreSQL
+// @Tags eco_tip
+// @Param new_data body item.GetEcoTipsRequest true "Request data"
+// @Success 200 {object} item.GetEcoTipsResponse
+// @Failure 500 {object} response.Envelope "Server error while getting eco tips"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/eco-tips [get]
+func _() {}
+
+// AddRating godoc
+// @Summary Adds a new rating
+// @Description Inserts new rating info into ratings table in PostgreSQL
+// @Tags rating
+// @Param new_data body item.AddRatingRequest true "New rating data"
+// @Success 200 {object} item.Rating
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while adding rating"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/ratings/add [post]
+func _() {}
+
+// GetRatings godoc
+// @Summary Gets all ratings
+// @Description Retrieves all ratings info from ratings table in PostgreSQL
+// @Tags rating
+// @Param new_data body item.GetRatingsRequest true "rating data"
+// @Success 200 {object} item.GetRatingsResponse
+// @Failure 500 {object} response.Envelope "Server error while getting ratings"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/ratings/GetAll [post]
+func _() {}
+
+// AddRecyclingCenter godoc
+// @Summary Adds a new recycling center
+// @Description Inserts new recycling center info into the database
+// @Tags recycling_center
+// @Param new_data body item.AddRecyclingCenterRequest true "New recycling center data"
+// @Success 200 {object} item.RecyclingCenterResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while adding recycling center"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/recyclings [post]
+func _() {}
+
+// SearchRecyclingCenters godoc
+// @Summary Searches for recycling centers
+// @Description Retrieves recycling centers based on search criteria
+// @Tags recycling_center
+// @Param search_criteria body item.SearchRecyclingCentersRequest true "Search criteria"
+// @Success 200 {object} item.ListRecyclingCentersResponse
+// @Failure 400 {object} response.Envelope "Invalid search criteria"
+// @Failure 500 {object} response.Envelope "Server error while searching recycling centers"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/recyclings/search [get]
+func _() {}
+
+// SubmitItemsForRecycling godoc
+// @Summary Submits items for recycling
+// @Description Inserts recycling submission info into the database
+// @Tags recycling
+// @Param new_data body item.SubmitItemsForRecyclingRequest true "New recycling submission data"
+// @Success 200 {object} item.RecyclingSubmissionResponse
+// @Failure 400 {object} response.Envelope "Invalid data"
+// @Failure 500 {object} response.Envelope "Server error while submitting items for recycling"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/recyclings [get]
+func _() {}
+
+// Statistics godoc
+// @Summary Gets statistics
+// @Description Retrieves various statistics from the service
+// @Tags statistics
+// @Param filter body item.GetStatisticsRequest true "Statistics filter"
+// @Success 200 {object} item.GetStatisticsResponse
+// @Failure 400 {object} response.Envelope "Invalid filter"
+// @Failure 500 {object} response.Envelope "Server error while getting statistics"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/statistics [post]
+func _() {}
+
+// SendSwapRequest godoc
+// @Summary Send swap request
+// @Description Sends a swap request to the service
+// @Tags swap
+// @Param swap body item.SendSwapRequestRequest true "Swap request info"
+// @Success 200 {object} item.SwapResponse
+// @Failure 400 {object} response.Envelope "Invalid request data"
+// @Failure 500 {object} response.Envelope "Server error while sending swap request"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/swaps/ [post]
+func _() {}
+
+// AcceptSwapRequest godoc
+// @Summary Accept swap request
+// @Description Accepts a swap request
+// @Tags swap
+// @Param swap body item.AcceptSwapRequestRequest true "Swap request info"
+// @Success 200 {object} item.SwapResponse
+// @Failure 400 {object} response.Envelope "Invalid request data"
+// @Failure 500 {object} response.Envelope "Server error while accepting swap request"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/swaps/accept [put]
+func _() {}
+
+// RejectSwapRequest godoc
+// @Summary Reject swap request
+// @Description Rejects a swap request
+// @Tags swap
+// @Param swap body item.RejectSwapRequestRequest true "Swap request info"
+// @Success 200 {object} item.SwapResponse
+// @Failure 400 {object} response.Envelope "Invalid request data"
+// @Failure 500 {object} response.Envelope "Server error while rejecting swap request"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/swaps/reject [put]
+func _() {}
+
+// ListSwapRequests godoc
+// @Summary List swap requests
+// @Description Lists all swap requests
+// @Tags swap
+// @Param swap_id path string true "Not read; the filter is in the body"
+// @Param filter body item.ListSwapRequestsRequest true "Swap request filter"
+// @Success 200 {object} item.ListSwapRequestsResponse
+// @Failure 400 {object} response.Envelope "Invalid filter"
+// @Failure 500 {object} response.Envelope "Server error while listing swap requests"
+// @Failure 504 {object} response.Envelope "Backend did not respond in time"
+// @Security BearerAuth
+// @Deprecated
+// @Router /item-system/swaps/{swap_id} [put]
+func _() {}
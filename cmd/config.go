@@ -0,0 +1,36 @@
+package main
+
+import (
+	"api-gateway/config"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// checkConfig implements "config check": it loads and validates the
+// config like the gateway would and prints the effective settings with
+// secrets masked. It returns the exit status.
+func checkConfig(args []string) int {
+	cfg, shadowed, err := config.LoadShadowed(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	out, err := cfg.Dump()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	os.Stdout.Write(out)
+	if len(shadowed) > 0 {
+		fmt.Fprintln(os.Stderr, "overridden by the environment or flags:", strings.Join(shadowed, ", "))
+	}
+	return 0
+}
@@ -3,11 +3,79 @@ package main
 import (
 	"api-gateway/api"
 	"api-gateway/config"
+	"api-gateway/pkg"
+	"api-gateway/pkg/logger"
+	"api-gateway/pkg/reload"
+	"api-gateway/pkg/tracing"
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
-	cfg := config.Load()
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "check" {
+		os.Exit(checkConfig(os.Args[3:]))
+	}
 
-	router := api.NewRouter(cfg)
-	router.Run(cfg.HTTP_PORT)
-}
\ No newline at end of file
+	cfg, shadowed, err := config.LoadShadowed(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("error loading config: %v", err)
+	}
+
+	lg, err := logger.NewLogger(cfg)
+	if err != nil {
+		log.Fatalf("error setting up logging: %v", err)
+	}
+	defer lg.Close()
+
+	// Route the standard logger through the configured handler as well.
+	slog.SetDefault(lg.Logger)
+
+	if len(shadowed) > 0 {
+		lg.Logger.Warn("config file settings are overridden by the environment or flags", "keys", shadowed)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("error setting up tracing: %v", err)
+	}
+
+	clients, err := pkg.NewClients(cfg)
+	if err != nil {
+		log.Fatalf("error connecting to backends: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	reloader := reload.New(cfg, os.Args[1:], lg.Logger)
+	reloader.Register(lg, clients)
+	go reloader.Run(ctx)
+
+	err = api.NewServer(cfg, clients, lg, reloader).Run(ctx)
+	if err != nil {
+		log.Println(err)
+	}
+
+	err = clients.Close()
+	if err != nil {
+		log.Println(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = shutdownTracing(ctx)
+	if err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,209 @@
+// Command replay sends the requests of a journal written by the gateway
+// (JOURNAL_ENABLED) to a gateway instance and reports every response
+// whose status or body differs from the journaled one.
+//
+//	go run ./cmd/replay -journal journal.jsonl -target http://localhost:8080 -token $TOKEN
+//
+// It exits with status 1 when there are regressions.
+package main
+
+import (
+	"api-gateway/pkg/journal"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func main() {
+	journalFile := flag.String("journal", "journal.jsonl", "journal to replay")
+	target := flag.String("target", "http://localhost:8080", "base URL of the gateway")
+	token := flag.String("token", "", "bearer token sent with every request")
+	ignore := flag.String("ignore", "request_id,created_at,updated_at", "comma-separated JSON keys left out of the comparison")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of a single request")
+	flag.Parse()
+
+	file, err := os.Open(*journalFile)
+	if err != nil {
+		log.Fatalf("error opening journal: %v", err)
+	}
+	defer file.Close()
+
+	r := replayer{
+		target: strings.TrimSuffix(*target, "/"),
+		token:  *token,
+		ignore: map[string]bool{},
+		client: &http.Client{Timeout: *timeout},
+	}
+	for _, key := range strings.Split(*ignore, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			r.ignore[key] = true
+		}
+	}
+
+	err = journal.Read(file, r.replay)
+	if err != nil {
+		log.Fatalf("error replaying journal: %v", err)
+	}
+
+	fmt.Printf("%d passed, %d failed, %d skipped\n", r.passed, r.failed, r.skipped)
+	if r.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+type replayer struct {
+	target string
+	token  string
+	ignore map[string]bool
+	client *http.Client
+
+	passed, failed, skipped int
+}
+
+func (r *replayer) replay(e journal.Entry) error {
+	name := e.Method + " " + e.Path
+
+	if e.BodyTruncated {
+		fmt.Printf("SKIP %s: request body was not journaled\n", name)
+		r.skipped++
+		return nil
+	}
+	if e.BodyRedacted {
+		fmt.Printf("SKIP %s: request body was redacted in the journal\n", name)
+		r.skipped++
+		return nil
+	}
+
+	status, body, err := r.send(e)
+	if err != nil {
+		fmt.Printf("FAIL %s: %v\n", name, err)
+		r.failed++
+		return nil
+	}
+
+	var diffs []string
+	if status != e.Status {
+		diffs = append(diffs, fmt.Sprintf("status: %d -> %d", e.Status, status))
+	}
+	if e.ResponseTruncated {
+		fmt.Printf("NOTE %s: response not journaled, comparing the status only\n", name)
+	} else {
+		diffs = append(diffs, r.diff(e.Response, journal.Sanitize(body))...)
+	}
+
+	if len(diffs) == 0 {
+		r.passed++
+		return nil
+	}
+
+	r.failed++
+	fmt.Printf("FAIL %s\n", name)
+	for _, d := range diffs {
+		fmt.Printf("    %s\n", d)
+	}
+	return nil
+}
+
+func (r *replayer) send(e journal.Entry) (int, []byte, error) {
+	var body io.Reader
+	switch {
+	case len(e.Body) > 0:
+		body = bytes.NewReader(e.Body)
+	case len(e.BodyRaw) > 0:
+		body = bytes.NewReader(e.BodyRaw)
+	}
+
+	req, err := http.NewRequest(e.Method, r.target+e.Path, body)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to build request")
+	}
+	for name, value := range e.Headers {
+		req.Header.Set(name, value)
+	}
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "request failed")
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to read response")
+	}
+	return resp.StatusCode, b, nil
+}
+
+// diff compares two sanitized bodies and describes every difference by
+// its JSON path, skipping ignored keys.
+func (r *replayer) diff(want, got json.RawMessage) []string {
+	var w, g interface{}
+	if len(want) > 0 {
+		json.Unmarshal(want, &w)
+	}
+	if len(got) > 0 {
+		json.Unmarshal(got, &g)
+	}
+
+	var diffs []string
+	r.diffValue("$", w, g, &diffs)
+	return diffs
+}
+
+func (r *replayer) diffValue(path string, want, got interface{}, diffs *[]string) {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for key, wv := range w {
+			if r.ignore[key] {
+				continue
+			}
+			gv, ok := g[key]
+			if !ok {
+				*diffs = append(*diffs, fmt.Sprintf("%s.%s: missing", path, key))
+				continue
+			}
+			r.diffValue(path+"."+key, wv, gv, diffs)
+		}
+		for key := range g {
+			if _, ok := w[key]; !ok && !r.ignore[key] {
+				*diffs = append(*diffs, fmt.Sprintf("%s.%s: unexpected", path, key))
+			}
+		}
+		return
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok {
+			break
+		}
+		if len(w) != len(g) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: length %d -> %d", path, len(w), len(g)))
+			return
+		}
+		for i := range w {
+			r.diffValue(fmt.Sprintf("%s[%d]", path, i), w[i], g[i], diffs)
+		}
+		return
+	}
+
+	wb, _ := json.Marshal(want)
+	gb, _ := json.Marshal(got)
+	if !bytes.Equal(wb, gb) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: %s -> %s", path, wb, gb))
+	}
+}
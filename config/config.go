@@ -1,38 +1,209 @@
 package config
 
 import (
-	"log"
-	"os"
-
-	"github.com/joho/godotenv"
-	"github.com/spf13/cast"
+	"strings"
+	"time"
 )
 
+// DateLayout is the format of the date settings.
+const DateLayout = "2006-01-02"
+
+// Config holds every setting of the gateway. Each field is read, in order
+// of precedence, from a command line flag named after its key, the
+// environment variable named after the field, the config file under its
+// key, the .env entry named after the field, and finally its default.
+//
+// Fields tagged secret are masked when the config is printed. Fields tagged
+// reload take effect when the config is reloaded; the others need a
+// restart.
 type Config struct {
-	HTTP_PORT                string
-	USER_SERVICE_PORT        string
-	ITEM_SERVICE_PORT string
-}
+	HTTP_PORT                string        `key:"server.http_port" default:":8080"`
+	HTTP_READ_TIMEOUT        time.Duration `key:"server.read_timeout" default:"10s"`
+	HTTP_READ_HEADER_TIMEOUT time.Duration `key:"server.read_header_timeout" default:"5s"`
+	HTTP_WRITE_TIMEOUT       time.Duration `key:"server.write_timeout" default:"30s"`
+	HTTP_IDLE_TIMEOUT        time.Duration `key:"server.idle_timeout" default:"60s"`
+	SHUTDOWN_DRAIN_DELAY     time.Duration `key:"server.shutdown.drain_delay" default:"5s"`
+	SHUTDOWN_TIMEOUT         time.Duration `key:"server.shutdown.timeout" default:"30s"`
 
-func Load() *Config {
-	err := godotenv.Load(".env")
-	if err != nil {
-		log.Fatalf("error loading .env: %v", err)
-	}
+	// HTTP_TRUSTED_PROXIES lists the proxies, as IPs or CIDRs, whose
+	// X-Forwarded-For header is believed for the client IP. By default no
+	// proxy is trusted and the client IP is the peer address.
+	HTTP_TRUSTED_PROXIES []string `key:"server.trusted_proxies"`
+
+	// TIMEOUTS maps a route ("GET /item-system/items/:item_id") or an RPC
+	// method ("GetItem") to the time its backend call may take.
+	TIMEOUT_DEFAULT time.Duration     `key:"timeouts.default" default:"5s" reload:"true"`
+	TIMEOUTS        map[string]string `key:"timeouts.overrides" reload:"true"`
+
+	USER_SERVICE_PORT               string        `key:"backends.user.address" default:":50051"`
+	USER_SERVICE_TLS                bool          `key:"backends.user.tls.enabled" default:"false"`
+	USER_SERVICE_TLS_CA_FILE        string        `key:"backends.user.tls.ca_file"`
+	USER_SERVICE_TLS_SERVER_NAME    string        `key:"backends.user.tls.server_name"`
+	USER_SERVICE_TIMEOUT            time.Duration `key:"backends.user.timeout" default:"0s" reload:"true"`
+	USER_SERVICE_RETRY_MAX_ATTEMPTS int           `key:"backends.user.retry.max_attempts" default:"0"`
+
+	ITEM_SERVICE_PORT               string        `key:"backends.item.address" default:":50052"`
+	ITEM_SERVICE_TLS                bool          `key:"backends.item.tls.enabled" default:"false"`
+	ITEM_SERVICE_TLS_CA_FILE        string        `key:"backends.item.tls.ca_file"`
+	ITEM_SERVICE_TLS_SERVER_NAME    string        `key:"backends.item.tls.server_name"`
+	ITEM_SERVICE_TIMEOUT            time.Duration `key:"backends.item.timeout" default:"0s" reload:"true"`
+	ITEM_SERVICE_RETRY_MAX_ATTEMPTS int           `key:"backends.item.retry.max_attempts" default:"0"`
+
+	AUTH_SERVICE_PORT               string        `key:"backends.auth.address" default:":50050"`
+	AUTH_SERVICE_TLS                bool          `key:"backends.auth.tls.enabled" default:"false"`
+	AUTH_SERVICE_TLS_CA_FILE        string        `key:"backends.auth.tls.ca_file"`
+	AUTH_SERVICE_TLS_SERVER_NAME    string        `key:"backends.auth.tls.server_name"`
+	AUTH_SERVICE_TIMEOUT            time.Duration `key:"backends.auth.timeout" default:"0s" reload:"true"`
+	AUTH_SERVICE_RETRY_MAX_ATTEMPTS int           `key:"backends.auth.retry.max_attempts" default:"0"`
+
+	// SERVICE_BACKENDS names the backend of each gRPC service, for the gRPC
+	// proxy and dynamic routes.
+	SERVICE_BACKENDS map[string]string `key:"backends.services" default:"authentication.Authentication=auth,user.UserService=user,item.ItemService=item"`
+
+	// GRPC_ENABLED serves gRPC on HTTP_PORT next to the HTTP API, and
+	// gRPC-Web with GRPC_WEB_ENABLED, forwarding each call to the backend
+	// of its service.
+	GRPC_ENABLED     bool `key:"grpc.enabled" default:"false"`
+	GRPC_WEB_ENABLED bool `key:"grpc.web.enabled" default:"false"`
+
+	JWT_SECRET                string        `key:"auth.jwt.secret" secret:"true" reload:"true"`
+	JWT_PUBLIC_KEY_FILE       string        `key:"auth.jwt.public_key_file" reload:"true"`
+	JWT_JWKS_URL              string        `key:"auth.jwt.jwks_url" reload:"true"`
+	JWT_JWKS_REFRESH_INTERVAL time.Duration `key:"auth.jwt.jwks_refresh_interval" default:"1h" reload:"true"`
+	JWT_ALGORITHMS            []string      `key:"auth.jwt.algorithms" default:"HS256" reload:"true"`
+	JWT_ISSUER                string        `key:"auth.jwt.issuer" reload:"true"`
+	JWT_AUDIENCE              string        `key:"auth.jwt.audience" reload:"true"`
+	JWT_CLOCK_SKEW            time.Duration `key:"auth.jwt.clock_skew" default:"30s" reload:"true"`
+	JWT_SUBJECT_CLAIM         string        `key:"auth.jwt.subject_claim" default:"sub" reload:"true"`
+	JWT_ROLES_CLAIM           string        `key:"auth.jwt.roles_claim" default:"roles" reload:"true"`
+
+	POLICY_FILE string `key:"auth.policy_file"`
+
+	PROTOJSON_EMIT_UNPOPULATED bool `key:"protojson.emit_unpopulated" default:"true"`
+	PROTOJSON_USE_PROTO_NAMES  bool `key:"protojson.use_proto_names" default:"true"`
+	PROTOJSON_DISCARD_UNKNOWN  bool `key:"protojson.discard_unknown" default:"false"`
+
+	// DYNAMIC_ROUTES maps an HTTP route ("GET /v2/things/{thing_id}") to a
+	// unary method ("item.ThingService/GetThing") found in the descriptor
+	// set or through server reflection on the DYNAMIC_REFLECTION backends.
+	DYNAMIC_DESCRIPTOR_SET string            `key:"dynamic.descriptor_set"`
+	DYNAMIC_REFLECTION     []string          `key:"dynamic.reflection"`
+	DYNAMIC_ROUTES         map[string]string `key:"dynamic.routes"`
+
+	// PATCH_BACKENDS names the backends that apply the update_mask of
+	// their update requests. PATCH routes that fill in update_mask are
+	// only served for them: a backend that ignores the mask would clear
+	// the fields missing from the body. Remove backends that run a build
+	// older than the protos.
+	PATCH_BACKENDS []string `key:"patch.backends" default:"user,item"`
+
+	// LEGACY_DEPRECATED_AT and LEGACY_SUNSET (YYYY-MM-DD) are announced in
+	// the Deprecation and Sunset headers of the /item-system routes, which
+	// /v1 replaces. They are set for each release. The routes are marked
+	// deprecated without a date too; an empty sunset is not sent.
+	LEGACY_DEPRECATED_AT string `key:"legacy.deprecated_at"`
+	LEGACY_SUNSET        string `key:"legacy.sunset"`
 
-	cfg := Config{}
+	// API_KEYS are the keys a client may send in X-API-Key to be rate
+	// limited by key instead of by IP. Other keys are ignored.
+	API_KEYS []string `key:"rate_limits.api_keys" secret:"true" reload:"true"`
 
-	cfg.HTTP_PORT = cast.ToString(coalesce("HTTP_PORT", ":8080"))
-	cfg.USER_SERVICE_PORT = cast.ToString(coalesce("USER_SERVICE_PORT", ":50051"))
-	cfg.ITEM_SERVICE_PORT = cast.ToString(coalesce("ITEM_SERVICE_PORT", ":50052"))
+	RATE_LIMIT_ENABLED bool              `key:"rate_limits.enabled" default:"true" reload:"true"`
+	RATE_LIMITS        map[string]string `key:"rate_limits.groups" default:"default=100/1m,auth=10/1m" reload:"true"`
 
-	return &cfg
+	RETRY_MAX_ATTEMPTS        int           `key:"retry.max_attempts" default:"3"`
+	RETRY_INITIAL_BACKOFF     time.Duration `key:"retry.initial_backoff" default:"100ms"`
+	RETRY_MAX_BACKOFF         time.Duration `key:"retry.max_backoff" default:"1s"`
+	BREAKER_FAILURE_THRESHOLD int           `key:"circuit_breaker.failure_threshold" default:"5"`
+	BREAKER_OPEN_TIMEOUT      time.Duration `key:"circuit_breaker.open_timeout" default:"30s"`
+
+	HEALTH_CHECK_TIMEOUT time.Duration `key:"health.check_timeout" default:"1s"`
+
+	CONFIG_WATCH_INTERVAL time.Duration `key:"config.watch_interval" default:"5s"`
+
+	TRACING_EXPORTER      string  `key:"tracing.exporter" default:"none"`
+	TRACING_SERVICE_NAME  string  `key:"tracing.service_name" default:"api-gateway"`
+	TRACING_OTLP_ENDPOINT string  `key:"tracing.otlp.endpoint" default:"localhost:4317"`
+	TRACING_OTLP_INSECURE bool    `key:"tracing.otlp.insecure" default:"true"`
+	TRACING_SAMPLE_RATIO  float64 `key:"tracing.sample_ratio" default:"1.0"`
+
+	LOG_FORMAT          string        `key:"logging.format" default:"text"`
+	LOG_LEVEL           string        `key:"logging.level" default:"info" reload:"true"`
+	LOG_OUTPUT          string        `key:"logging.output" default:"file"`
+	LOG_FILE            string        `key:"logging.file" default:"app.log"`
+	LOG_MAX_SIZE_MB     int           `key:"logging.rotation.max_size_mb" default:"100"`
+	LOG_ROTATE_INTERVAL time.Duration `key:"logging.rotation.interval" default:"24h"`
+	LOG_MAX_BACKUPS     int           `key:"logging.rotation.max_backups" default:"7"`
+	LOG_MAX_AGE         time.Duration `key:"logging.rotation.max_age" default:"168h"`
+
+	ACCESS_LOG_ENABLED     bool    `key:"logging.access.enabled" default:"true" reload:"true"`
+	ACCESS_LOG_FORMAT      string  `key:"logging.access.format" default:"json"`
+	ACCESS_LOG_OUTPUT      string  `key:"logging.access.output" default:"stdout"`
+	ACCESS_LOG_FILE        string  `key:"logging.access.file" default:"access.log"`
+	ACCESS_LOG_SAMPLE_RATE float64 `key:"logging.access.sample_rate" default:"1.0" reload:"true"`
+
+	JOURNAL_ENABLED        bool     `key:"journal.enabled" default:"false"`
+	JOURNAL_FILE           string   `key:"journal.file" default:"journal.jsonl"`
+	JOURNAL_HEADERS        []string `key:"journal.headers" default:"Content-Type,Accept,X-Request-ID"`
+	JOURNAL_MAX_BODY_BYTES int      `key:"journal.max_body_bytes" default:"65536"`
+
+	CORS_ALLOWED_ORIGINS   []string      `key:"cors.allowed_origins" reload:"true"`
+	CORS_ALLOWED_METHODS   []string      `key:"cors.allowed_methods" default:"GET,POST,PUT,PATCH,DELETE" reload:"true"`
+	CORS_ALLOWED_HEADERS   []string      `key:"cors.allowed_headers" default:"Authorization,Content-Type,X-Request-ID,X-Request-Timeout,X-API-Key,X-Grpc-Web,X-User-Agent,Grpc-Timeout" reload:"true"`
+	CORS_EXPOSED_HEADERS   []string      `key:"cors.exposed_headers" default:"X-Request-ID,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After,Grpc-Status,Grpc-Message,Deprecation,Sunset" reload:"true"`
+	CORS_ALLOW_CREDENTIALS bool          `key:"cors.allow_credentials" default:"false" reload:"true"`
+	CORS_MAX_AGE           time.Duration `key:"cors.max_age" default:"10m" reload:"true"`
+}
+
+// Backend is the connection setting of one backend service.
+type Backend struct {
+	Name             string
+	Address          string
+	TLS              bool
+	TLSCAFile        string
+	TLSServerName    string
+	Timeout          time.Duration
+	RetryMaxAttempts int
+}
+
+// Backends returns the settings of the user, item and auth services.
+// Services without their own retry limit use RETRY_MAX_ATTEMPTS.
+func (c *Config) Backends() []Backend {
+	backends := []Backend{
+		{"user", c.USER_SERVICE_PORT, c.USER_SERVICE_TLS, c.USER_SERVICE_TLS_CA_FILE,
+			c.USER_SERVICE_TLS_SERVER_NAME, c.USER_SERVICE_TIMEOUT, c.USER_SERVICE_RETRY_MAX_ATTEMPTS},
+		{"item", c.ITEM_SERVICE_PORT, c.ITEM_SERVICE_TLS, c.ITEM_SERVICE_TLS_CA_FILE,
+			c.ITEM_SERVICE_TLS_SERVER_NAME, c.ITEM_SERVICE_TIMEOUT, c.ITEM_SERVICE_RETRY_MAX_ATTEMPTS},
+		{"auth", c.AUTH_SERVICE_PORT, c.AUTH_SERVICE_TLS, c.AUTH_SERVICE_TLS_CA_FILE,
+			c.AUTH_SERVICE_TLS_SERVER_NAME, c.AUTH_SERVICE_TIMEOUT, c.AUTH_SERVICE_RETRY_MAX_ATTEMPTS},
+	}
+	for i := range backends {
+		if backends[i].RetryMaxAttempts == 0 {
+			backends[i].RetryMaxAttempts = c.RETRY_MAX_ATTEMPTS
+		}
+	}
+	return backends
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
 }
 
-func coalesce(key string, value interface{}) interface{} {
-	val, exists := os.LookupEnv(key)
-	if exists {
-		return val
+// splitMap parses "key=value" pairs separated by commas.
+func splitMap(value string) map[string]string {
+	m := map[string]string{}
+	for _, item := range splitList(value) {
+		key, val, found := strings.Cut(item, "=")
+		if found {
+			m[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
 	}
-	return value
+	return m
 }
@@ -0,0 +1,405 @@
+package config
+
+import (
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the environment variable that points at the config file
+// when no -config flag is given.
+const FileEnv = "CONFIG_FILE"
+
+const masked = "********"
+
+// field describes one Config field and where its value comes from.
+type field struct {
+	index  int
+	key    string
+	env    string
+	def    string
+	hasDef bool
+	secret bool
+	reload bool
+	typ    reflect.Type
+}
+
+var fields = configFields()
+
+func configFields() []field {
+	t := reflect.TypeOf(Config{})
+	list := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		def, hasDef := sf.Tag.Lookup("default")
+		list = append(list, field{
+			index:  i,
+			key:    sf.Tag.Get("key"),
+			env:    sf.Name,
+			def:    def,
+			hasDef: hasDef,
+			secret: sf.Tag.Get("secret") == "true",
+			reload: sf.Tag.Get("reload") == "true",
+			typ:    sf.Type,
+		})
+	}
+	return list
+}
+
+// Load builds the config from its defaults, a .env file in the working
+// directory, the config file, the environment and the flags in args, each
+// layer overriding the previous one, and validates the result. A missing
+// .env file is not an error.
+func Load(args []string) (*Config, error) {
+	cfg, _, err := LoadShadowed(args)
+	return cfg, err
+}
+
+// LoadShadowed is Load that also returns the keys set in the config file
+// whose value the environment or a flag overrides, so that edits to them
+// can be reported as having no effect.
+func LoadShadowed(args []string) (*Config, []string, error) {
+	dotenv, err := readDotEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path, overrides, err := parseFlags(args, dotenv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg := Config{}
+	v := reflect.ValueOf(&cfg).Elem()
+
+	for _, f := range fields {
+		if !f.hasDef {
+			continue
+		}
+		err := f.set(v, f.def)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid default for %s", f.key)
+		}
+	}
+
+	for _, f := range fields {
+		raw, ok := dotenv[f.env]
+		if !ok {
+			continue
+		}
+		err := f.set(v, raw)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, ".env: invalid value for %s", f.env)
+		}
+	}
+
+	var values map[string]interface{}
+	if path != "" {
+		values, err = readFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, f := range fields {
+			raw, ok := values[f.key]
+			if !ok {
+				continue
+			}
+			err := f.set(v, raw)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "%s: invalid value for %s", path, f.key)
+			}
+		}
+	}
+	fromFile := cfg
+
+	for _, f := range fields {
+		raw, ok := os.LookupEnv(f.env)
+		if !ok {
+			continue
+		}
+		err := f.set(v, raw)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid value for environment variable %s", f.env)
+		}
+	}
+
+	for _, f := range fields {
+		raw, ok := overrides[f.key]
+		if !ok {
+			continue
+		}
+		err := f.set(v, raw)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid value for flag -%s", f.key)
+		}
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var shadowed []string
+	file := reflect.ValueOf(fromFile)
+	for _, f := range fields {
+		if _, ok := values[f.key]; !ok {
+			continue
+		}
+		if !reflect.DeepEqual(file.Field(f.index).Interface(), v.Field(f.index).Interface()) {
+			shadowed = append(shadowed, f.key)
+		}
+	}
+
+	return &cfg, shadowed, nil
+}
+
+// readDotEnv reads the .env file without adding it to the environment,
+// so that it ranks below the config file.
+func readDotEnv() (map[string]string, error) {
+	values, err := godotenv.Read(".env")
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load .env")
+	}
+	return values, nil
+}
+
+// FilePath returns the config file named by the -config flag in args, by
+// the environment or by .env, if any.
+func FilePath(args []string) (string, error) {
+	dotenv, err := readDotEnv()
+	if err != nil {
+		return "", err
+	}
+	path, _, err := parseFlags(args, dotenv)
+	return path, err
+}
+
+// parseFlags reads -config and one flag per config key, e.g.
+// -server.http_port=:9090. The config file defaults to the one named by
+// the environment, then by dotenv.
+func parseFlags(args []string, dotenv map[string]string) (string, map[string]string, error) {
+	file, ok := os.LookupEnv(FileEnv)
+	if !ok {
+		file = dotenv[FileEnv]
+	}
+
+	set := flag.NewFlagSet("api-gateway", flag.ContinueOnError)
+	path := set.String("config", file, "path of a YAML or TOML config file")
+
+	overrides := map[string]string{}
+	for _, f := range fields {
+		key := f.key
+		set.Func(key, "overrides "+f.env, func(value string) error {
+			overrides[key] = value
+			return nil
+		})
+	}
+
+	err := set.Parse(args)
+	if err != nil {
+		return "", nil, err
+	}
+	if set.NArg() > 0 {
+		return "", nil, errors.Errorf("unexpected arguments %q", set.Args())
+	}
+
+	return *path, overrides, nil
+}
+
+// readFile parses a YAML or TOML config file into a map from dotted keys
+// to values. Keys that match no setting are reported, so that typos do
+// not go unnoticed.
+func readFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+
+	doc := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return nil, errors.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+
+	known := map[string]bool{}
+	for _, f := range fields {
+		known[f.key] = true
+	}
+
+	values := map[string]interface{}{}
+	var unknown []string
+	flatten("", doc, known, values, &unknown)
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, errors.Errorf("%s: unknown keys %s", path, strings.Join(unknown, ", "))
+	}
+
+	return values, nil
+}
+
+func flatten(prefix string, doc map[string]interface{}, known map[string]bool,
+	values map[string]interface{}, unknown *[]string) {
+	for name, value := range doc {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		if known[key] {
+			values[key] = value
+			continue
+		}
+
+		section, ok := value.(map[string]interface{})
+		if !ok {
+			*unknown = append(*unknown, key)
+			continue
+		}
+		flatten(key, section, known, values, unknown)
+	}
+}
+
+// set converts raw, a string from the environment, a flag or a default,
+// or a value decoded from the config file, to the type of the field.
+func (f field) set(cfg reflect.Value, raw interface{}) error {
+	v := cfg.Field(f.index)
+
+	switch v.Interface().(type) {
+	case time.Duration:
+		d, err := toDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case string:
+		s, err := cast.ToStringE(raw)
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case bool:
+		b, err := cast.ToBoolE(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case int:
+		i, err := cast.ToIntE(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(i))
+	case float64:
+		fl, err := cast.ToFloat64E(raw)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(fl)
+	case []string:
+		var list []string
+		if s, ok := raw.(string); ok {
+			list = splitList(s)
+		} else {
+			var err error
+			list, err = cast.ToStringSliceE(raw)
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	case map[string]string:
+		var m map[string]string
+		if s, ok := raw.(string); ok {
+			m = splitMap(s)
+		} else {
+			var err error
+			m, err = cast.ToStringMapStringE(raw)
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(reflect.ValueOf(m))
+	default:
+		return errors.Errorf("unsupported setting type %s", f.typ)
+	}
+	return nil
+}
+
+// toDuration reads durations with a unit, as in "5s". Unlike
+// cast.ToDurationE, it rejects bare numbers, which would otherwise be
+// taken as nanoseconds.
+func toDuration(raw interface{}) (time.Duration, error) {
+	switch raw := raw.(type) {
+	case time.Duration:
+		return raw, nil
+	case string:
+		return time.ParseDuration(strings.TrimSpace(raw))
+	}
+	return 0, errors.Errorf("duration %v has no unit, e.g. 5s", raw)
+}
+
+// Dump renders the config as YAML in the layout of the config file, with
+// secrets masked.
+func (c *Config) Dump() ([]byte, error) {
+	v := reflect.ValueOf(c).Elem()
+	doc := map[string]interface{}{}
+
+	for _, f := range fields {
+		var value interface{}
+		switch fv := v.Field(f.index).Interface().(type) {
+		case time.Duration:
+			value = fv.String()
+		default:
+			value = fv
+		}
+		if f.secret && !v.Field(f.index).IsZero() {
+			value = masked
+		}
+
+		section := doc
+		parts := strings.Split(f.key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := section[part].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				section[part] = next
+			}
+			section = next
+		}
+		section[parts[len(parts)-1]] = value
+	}
+
+	return yaml.Marshal(doc)
+}
+
+// describe names a setting by its config key and environment variable
+// for error messages.
+func describe(name string) string {
+	for _, f := range fields {
+		if f.env == name {
+			return f.key + " (" + f.env + ")"
+		}
+	}
+	return name
+}
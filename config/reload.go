@@ -0,0 +1,39 @@
+package config
+
+import "reflect"
+
+// Reloadable returns next with every setting that needs a restart taken
+// from c, so that it can be applied to a running gateway. It also returns
+// the keys of those settings that differ between c and next.
+func (c *Config) Reloadable(next *Config) (*Config, []string) {
+	merged := *next
+	cur := reflect.ValueOf(c).Elem()
+	v := reflect.ValueOf(&merged).Elem()
+
+	var ignored []string
+	for _, f := range fields {
+		if f.reload {
+			continue
+		}
+		if !reflect.DeepEqual(cur.Field(f.index).Interface(), v.Field(f.index).Interface()) {
+			ignored = append(ignored, f.key)
+			v.Field(f.index).Set(cur.Field(f.index))
+		}
+	}
+
+	return &merged, ignored
+}
+
+// Changed returns the keys of the settings that differ between c and next.
+func (c *Config) Changed(next *Config) []string {
+	cur := reflect.ValueOf(c).Elem()
+	v := reflect.ValueOf(next).Elem()
+
+	var keys []string
+	for _, f := range fields {
+		if !reflect.DeepEqual(cur.Field(f.index).Interface(), v.Field(f.index).Interface()) {
+			keys = append(keys, f.key)
+		}
+	}
+	return keys
+}
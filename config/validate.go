@@ -0,0 +1,231 @@
+package config
+
+import (
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"api-gateway/pkg/ratelimit"
+	"api-gateway/pkg/transcode"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// publishedSecrets were shipped as samples and must not sign tokens.
+var publishedSecrets = map[string]bool{
+	"visca barsa": true,
+	"change-me":   true,
+}
+
+// Validate reports every problem with the config at once, naming each
+// setting by its config key and environment variable.
+func (c *Config) Validate() error {
+	v := validator{}
+
+	v.required("HTTP_PORT", c.HTTP_PORT)
+	v.notNegative("HTTP_READ_TIMEOUT", c.HTTP_READ_TIMEOUT)
+	v.positive("HTTP_READ_HEADER_TIMEOUT", c.HTTP_READ_HEADER_TIMEOUT)
+	v.notNegative("HTTP_WRITE_TIMEOUT", c.HTTP_WRITE_TIMEOUT)
+	v.notNegative("HTTP_IDLE_TIMEOUT", c.HTTP_IDLE_TIMEOUT)
+	v.notNegative("SHUTDOWN_DRAIN_DELAY", c.SHUTDOWN_DRAIN_DELAY)
+	v.positive("SHUTDOWN_TIMEOUT", c.SHUTDOWN_TIMEOUT)
+	for _, proxy := range c.HTTP_TRUSTED_PROXIES {
+		_, _, err := net.ParseCIDR(proxy)
+		v.check(err == nil || net.ParseIP(proxy) != nil, "HTTP_TRUSTED_PROXIES", proxy+": is not an IP or CIDR")
+	}
+
+	v.positive("TIMEOUT_DEFAULT", c.TIMEOUT_DEFAULT)
+	for key, value := range c.TIMEOUTS {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			v.problem("TIMEOUTS", key+": must be a positive duration, e.g. 5s")
+			continue
+		}
+		if c.HTTP_WRITE_TIMEOUT > 0 {
+			v.check(d < c.HTTP_WRITE_TIMEOUT, "TIMEOUTS", key+": must be shorter than server.write_timeout")
+		}
+	}
+	if c.HTTP_WRITE_TIMEOUT > 0 {
+		v.check(c.TIMEOUT_DEFAULT < c.HTTP_WRITE_TIMEOUT, "TIMEOUT_DEFAULT", "must be shorter than server.write_timeout")
+	}
+
+	for _, b := range c.Backends() {
+		prefix := strings.ToUpper(b.Name) + "_SERVICE_"
+		v.required(prefix+"PORT", b.Address)
+		v.notNegative(prefix+"TIMEOUT", b.Timeout)
+		v.check(b.RetryMaxAttempts >= 0, prefix+"RETRY_MAX_ATTEMPTS", "must not be negative")
+		if b.TLSCAFile != "" {
+			v.fileExists(prefix+"TLS_CA_FILE", b.TLSCAFile)
+			v.check(b.TLS, prefix+"TLS_CA_FILE", "is set but TLS is not enabled")
+		}
+	}
+
+	if c.JWT_SECRET == "" && c.JWT_PUBLIC_KEY_FILE == "" && c.JWT_JWKS_URL == "" {
+		v.problem("JWT_SECRET", "is required unless auth.jwt.public_key_file or auth.jwt.jwks_url is set")
+	}
+	v.check(!publishedSecrets[c.JWT_SECRET], "JWT_SECRET", "is a sample value published with the source; generate a secret of your own")
+	if c.JWT_PUBLIC_KEY_FILE != "" {
+		v.fileExists("JWT_PUBLIC_KEY_FILE", c.JWT_PUBLIC_KEY_FILE)
+	}
+	if c.JWT_JWKS_URL != "" {
+		v.positive("JWT_JWKS_REFRESH_INTERVAL", c.JWT_JWKS_REFRESH_INTERVAL)
+	}
+	v.check(len(c.JWT_ALGORITHMS) > 0, "JWT_ALGORITHMS", "must list at least one algorithm")
+	v.notNegative("JWT_CLOCK_SKEW", c.JWT_CLOCK_SKEW)
+	v.required("JWT_SUBJECT_CLAIM", c.JWT_SUBJECT_CLAIM)
+	if c.POLICY_FILE != "" {
+		v.fileExists("POLICY_FILE", c.POLICY_FILE)
+	}
+
+	backends := []string{}
+	for _, b := range c.Backends() {
+		backends = append(backends, b.Name)
+	}
+	if c.DYNAMIC_DESCRIPTOR_SET != "" {
+		v.fileExists("DYNAMIC_DESCRIPTOR_SET", c.DYNAMIC_DESCRIPTOR_SET)
+	}
+	for _, backend := range c.DYNAMIC_REFLECTION {
+		v.oneOf("DYNAMIC_REFLECTION", backend, backends...)
+	}
+	for service, backend := range c.SERVICE_BACKENDS {
+		v.oneOf("SERVICE_BACKENDS", backend, backends...)
+		v.check(protoreflect.FullName(service).IsValid(), "SERVICE_BACKENDS", service+": is not a service name")
+	}
+	for route, target := range c.DYNAMIC_ROUTES {
+		_, path, ok := transcode.ParseRoute(route)
+		if !ok {
+			v.problem("DYNAMIC_ROUTES", route+`: must look like "GET /v2/things/{thing_id}"`)
+		} else if _, err := transcode.ParseTemplate(path); err != nil {
+			v.problem("DYNAMIC_ROUTES", route+": "+err.Error())
+		}
+		_, _, ok = transcode.ParseTarget(target)
+		v.check(ok, "DYNAMIC_ROUTES", route+`: method must look like "package.Service/Method"`)
+	}
+
+	for _, backend := range c.PATCH_BACKENDS {
+		v.oneOf("PATCH_BACKENDS", backend, backends...)
+	}
+
+	deprecated := v.date("LEGACY_DEPRECATED_AT", c.LEGACY_DEPRECATED_AT)
+	sunset := v.date("LEGACY_SUNSET", c.LEGACY_SUNSET)
+	if !sunset.IsZero() && !deprecated.IsZero() {
+		v.check(sunset.After(deprecated), "LEGACY_SUNSET", "must be after legacy.deprecated_at")
+	}
+
+	for group, limit := range c.RATE_LIMITS {
+		_, err := ratelimit.ParseLimit(limit)
+		if err != nil {
+			v.problem("RATE_LIMITS", "group "+group+": "+err.Error())
+		}
+	}
+
+	v.check(c.RETRY_MAX_ATTEMPTS >= 1, "RETRY_MAX_ATTEMPTS", "must be at least 1")
+	v.positive("RETRY_INITIAL_BACKOFF", c.RETRY_INITIAL_BACKOFF)
+	v.check(c.RETRY_MAX_BACKOFF >= c.RETRY_INITIAL_BACKOFF, "RETRY_MAX_BACKOFF",
+		"must not be shorter than retry.initial_backoff")
+	v.check(c.BREAKER_FAILURE_THRESHOLD >= 1, "BREAKER_FAILURE_THRESHOLD", "must be at least 1")
+	v.positive("BREAKER_OPEN_TIMEOUT", c.BREAKER_OPEN_TIMEOUT)
+	v.positive("HEALTH_CHECK_TIMEOUT", c.HEALTH_CHECK_TIMEOUT)
+	v.notNegative("CONFIG_WATCH_INTERVAL", c.CONFIG_WATCH_INTERVAL)
+
+	v.oneOf("TRACING_EXPORTER", c.TRACING_EXPORTER, "none", "stdout", "otlp")
+	v.fraction("TRACING_SAMPLE_RATIO", c.TRACING_SAMPLE_RATIO)
+
+	v.oneOf("LOG_FORMAT", c.LOG_FORMAT, "text", "json")
+	v.oneOf("LOG_OUTPUT", c.LOG_OUTPUT, "stdout", "file")
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LOG_LEVEL)); err != nil {
+		v.problem("LOG_LEVEL", "must be one of debug, info, warn, error")
+	}
+	v.check(c.LOG_MAX_SIZE_MB >= 0, "LOG_MAX_SIZE_MB", "must not be negative")
+	v.check(c.LOG_MAX_BACKUPS >= 0, "LOG_MAX_BACKUPS", "must not be negative")
+	v.notNegative("LOG_ROTATE_INTERVAL", c.LOG_ROTATE_INTERVAL)
+	v.notNegative("LOG_MAX_AGE", c.LOG_MAX_AGE)
+
+	v.oneOf("ACCESS_LOG_FORMAT", c.ACCESS_LOG_FORMAT, "json", "common", "combined")
+	v.oneOf("ACCESS_LOG_OUTPUT", c.ACCESS_LOG_OUTPUT, "stdout", "file")
+	v.fraction("ACCESS_LOG_SAMPLE_RATE", c.ACCESS_LOG_SAMPLE_RATE)
+
+	if c.JOURNAL_ENABLED {
+		v.required("JOURNAL_FILE", c.JOURNAL_FILE)
+		v.check(c.JOURNAL_MAX_BODY_BYTES > 0, "JOURNAL_MAX_BODY_BYTES", "must be positive")
+	}
+
+	for _, origin := range c.CORS_ALLOWED_ORIGINS {
+		if origin == "*" && c.CORS_ALLOW_CREDENTIALS {
+			v.problem("CORS_ALLOWED_ORIGINS", `must list origins explicitly instead of "*" when credentials are allowed`)
+		}
+	}
+	v.notNegative("CORS_MAX_AGE", c.CORS_MAX_AGE)
+
+	return v.err()
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) problem(name, msg string) {
+	v.problems = append(v.problems, describe(name)+" "+msg)
+}
+
+func (v *validator) check(ok bool, name, msg string) {
+	if !ok {
+		v.problem(name, msg)
+	}
+}
+
+func (v *validator) required(name, value string) {
+	v.check(value != "", name, "is required")
+}
+
+func (v *validator) positive(name string, d time.Duration) {
+	v.check(d > 0, name, "must be positive, e.g. 5s")
+}
+
+func (v *validator) notNegative(name string, d time.Duration) {
+	v.check(d >= 0, name, "must not be negative")
+}
+
+func (v *validator) fraction(name string, f float64) {
+	v.check(f >= 0 && f <= 1, name, "must be between 0 and 1")
+}
+
+func (v *validator) oneOf(name, value string, allowed ...string) {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return
+		}
+	}
+	v.problem(name, "must be one of "+strings.Join(allowed, ", ")+", got "+`"`+value+`"`)
+}
+
+// date parses an optional YYYY-MM-DD date; empty values give the zero
+// time.
+func (v *validator) date(name, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		v.problem(name, "must be a date like 2006-01-02, got "+`"`+value+`"`)
+	}
+	return t
+}
+
+func (v *validator) fileExists(name, path string) {
+	_, err := os.Stat(path)
+	if err != nil {
+		v.problem(name, "points to an unreadable file: "+err.Error())
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return errors.New("invalid configuration:\n  - " + strings.Join(v.problems, "\n  - "))
+}
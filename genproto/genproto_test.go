@@ -0,0 +1,106 @@
+// Package genproto_test checks that the generated code is up to date with
+// the proto sources, so that edits to protos/ cannot be merged without
+// running make proto-gen.
+package genproto_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	_ "api-gateway/genproto/authentication"
+	_ "api-gateway/genproto/item"
+	_ "api-gateway/genproto/user"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// TestGeneratedCodeMatchesProtos compiles every file in protos/ with the
+// import paths of scripts/gen-proto.sh and compares the result with the
+// descriptor embedded in the generated code.
+func TestGeneratedCodeMatchesProtos(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "protos", "*", "*.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no proto sources found")
+	}
+
+	for _, path := range paths {
+		dir, name := filepath.Split(path)
+		t.Run(name, func(t *testing.T) {
+			compiler := protocompile.Compiler{
+				Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+					ImportPaths: []string{dir, filepath.Join("..", "protos"), filepath.Join("..", "third_party", "googleapis")},
+				}),
+			}
+			files, err := compiler.Compile(context.Background(), name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := normalize(t, protodesc.ToFileDescriptorProto(files[0]))
+			want.SourceCodeInfo = nil
+
+			generated, err := protoregistry.GlobalFiles.FindFileByPath(name)
+			if err != nil {
+				t.Fatalf("%s is not in genproto/, run make proto-gen", path)
+			}
+			got := normalize(t, protodesc.ToFileDescriptorProto(generated))
+
+			if !proto.Equal(got, want) {
+				t.Errorf("genproto/ is out of date with %s (%s), run make proto-gen", path, firstDifference(got, want))
+			}
+		})
+	}
+}
+
+// normalize decodes the options of fd with the generated extension types,
+// such as google.api.http, which the compiler represents by its own
+// dynamic messages.
+func normalize(t *testing.T, fd *descriptorpb.FileDescriptorProto) *descriptorpb.FileDescriptorProto {
+	t.Helper()
+	data, err := proto.Marshal(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &descriptorpb.FileDescriptorProto{}
+	err = proto.UnmarshalOptions{Resolver: protoregistry.GlobalTypes}.Unmarshal(data, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// firstDifference names the first message or service that differs
+// between two versions of a file.
+func firstDifference(got, want *descriptorpb.FileDescriptorProto) string {
+	messages := map[string]*descriptorpb.DescriptorProto{}
+	for _, m := range got.GetMessageType() {
+		messages[m.GetName()] = m
+	}
+	for _, m := range want.GetMessageType() {
+		if !proto.Equal(messages[m.GetName()], m) {
+			return "message " + m.GetName()
+		}
+	}
+
+	services := map[string]*descriptorpb.ServiceDescriptorProto{}
+	for _, s := range got.GetService() {
+		services[s.GetName()] = s
+	}
+	for _, s := range want.GetService() {
+		if !proto.Equal(services[s.GetName()], s) {
+			return "service " + s.GetName()
+		}
+	}
+
+	if len(got.GetMessageType()) != len(want.GetMessageType()) || len(got.GetService()) != len(want.GetService()) {
+		return "removed declarations"
+	}
+	return "file options or enums"
+}
@@ -2,36 +2,165 @@ package pkg
 
 import (
 	"api-gateway/config"
+	pba "api-gateway/genproto/authentication"
 	pbi "api-gateway/genproto/item"
 	pbu "api-gateway/genproto/user"
-	"log"
+	"api-gateway/pkg/health"
+	"api-gateway/pkg/identity"
+	"api-gateway/pkg/metrics"
+	"api-gateway/pkg/requestid"
+	"api-gateway/pkg/resilience"
+	"api-gateway/pkg/upstream"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-func NewUserClient(cfg *config.Config) pbu.UserServiceClient {
-	conn, err := grpc.NewClient(cfg.USER_SERVICE_PORT,
-		grpc.WithTransportCredentials(insecure.NewCredentials()))
+// idempotentMethods are safe to retry because they only read data.
+var idempotentMethods = []string{
+	pbu.UserService_GetUserProfile_FullMethodName,
+	pbu.UserService_GetUsers_FullMethodName,
+	pbu.UserService_GetEcoPoints_FullMethodName,
+	pbu.UserService_GetEcoPointsHistory_FullMethodName,
+	pbu.UserService_ValidateUserId_FullMethodName,
+	pbi.ItemService_GetItem_FullMethodName,
+	pbi.ItemService_ListItems_FullMethodName,
+	pbi.ItemService_SearchItems_FullMethodName,
+	pbi.ItemService_SearchRecyclingCenters_FullMethodName,
+	pbi.ItemService_ListSwapRequests_FullMethodName,
+	pbi.ItemService_GetRatings_FullMethodName,
+	pbi.ItemService_Statistics_FullMethodName,
+	pbi.ItemService_GetEcoTips_FullMethodName,
+}
+
+// Clients holds the connections to the backend services.
+type Clients struct {
+	User pbu.UserServiceClient
+	Item pbi.ItemServiceClient
+	Auth pba.AuthenticationClient
+
+	Conns    map[string]*grpc.ClientConn
+	Breakers map[string]*resilience.Breaker
+	Timeouts map[string]*resilience.Timeout
+	Health   *health.Checker
+}
 
-	if err != nil {
-		log.Println(errors.Wrap(err, "failed to connect to the address"))
-		return nil
+func NewClients(cfg *config.Config) (*Clients, error) {
+	c := Clients{
+		Conns:    map[string]*grpc.ClientConn{},
+		Breakers: map[string]*resilience.Breaker{},
+		Timeouts: map[string]*resilience.Timeout{},
 	}
 
-	return pbu.NewUserServiceClient(conn)
+	for _, b := range cfg.Backends() {
+		policy := resilience.RetryPolicy{
+			MaxAttempts:    b.RetryMaxAttempts,
+			InitialBackoff: cfg.RETRY_INITIAL_BACKOFF,
+			MaxBackoff:     cfg.RETRY_MAX_BACKOFF,
+			Multiplier:     2,
+			RetryableCodes: []codes.Code{codes.Unavailable},
+		}
+		policies := map[string]resilience.RetryPolicy{}
+		for _, method := range idempotentMethods {
+			policies[method] = policy
+		}
+
+		creds, err := transportCredentials(b)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+
+		breaker := resilience.NewBreaker(b.Name, cfg.BREAKER_FAILURE_THRESHOLD, cfg.BREAKER_OPEN_TIMEOUT)
+		timeout := resilience.NewTimeout(b.Timeout)
+
+		conn, err := grpc.NewClient(b.Address,
+			grpc.WithTransportCredentials(creds),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+			grpc.WithChainUnaryInterceptor(
+				identity.UnaryClientInterceptor,
+				requestid.UnaryClientInterceptor,
+				upstream.UnaryClientInterceptor,
+				health.Bypass(metrics.UnaryClientInterceptor(b.Name)),
+				health.Bypass(timeout.UnaryClientInterceptor),
+				health.Bypass(breaker.UnaryClientInterceptor),
+				health.Bypass(resilience.UnaryRetryInterceptor(policies)),
+			),
+			grpc.WithChainStreamInterceptor(
+				identity.StreamClientInterceptor,
+				requestid.StreamClientInterceptor,
+				metrics.StreamClientInterceptor(b.Name),
+				breaker.StreamClientInterceptor,
+			))
+		if err != nil {
+			c.Close()
+			return nil, errors.Wrapf(err, "failed to connect to the %s service", b.Name)
+		}
+
+		c.Conns[b.Name] = conn
+		c.Breakers[b.Name] = breaker
+		c.Timeouts[b.Name] = timeout
+	}
+
+	c.User = pbu.NewUserServiceClient(c.Conns["user"])
+	c.Item = pbi.NewItemServiceClient(c.Conns["item"])
+	c.Auth = pba.NewAuthenticationClient(c.Conns["auth"])
+	c.Health = health.NewChecker(c.Conns, cfg.HEALTH_CHECK_TIMEOUT)
+
+	return &c, nil
 }
 
+// Reload applies the backend timeouts in cfg. Addresses, TLS and retry
+// settings only change on restart.
+func (c *Clients) Reload(cfg *config.Config) error {
+	for _, b := range cfg.Backends() {
+		timeout, ok := c.Timeouts[b.Name]
+		if ok {
+			timeout.Set(b.Timeout)
+		}
+	}
+	return nil
+}
+
+func transportCredentials(b config.Backend) (credentials.TransportCredentials, error) {
+	if !b.TLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: b.TLSServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if b.TLSCAFile != "" {
+		pem, err := os.ReadFile(b.TLSCAFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the CA file of the %s service", b.Name)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates found in the CA file of the %s service", b.Name)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
 
-func NewItemClient(cfg *config.Config) pbi.ItemServiceClient {
-	conn, err := grpc.NewClient(cfg.ITEM_SERVICE_PORT,
-		grpc.WithTransportCredentials(insecure.NewCredentials()))
-		
-	if err != nil {
-		log.Println(errors.Wrap(err, "failed to connect to the address"))
-		return nil
+func (c *Clients) Close() error {
+	var first error
+	for name, conn := range c.Conns {
+		err := conn.Close()
+		if err != nil && first == nil {
+			first = errors.Wrapf(err, "failed to close the %s connection", name)
+		}
 	}
-	
-	return pbi.NewItemServiceClient(conn)
+	return first
 }
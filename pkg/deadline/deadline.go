@@ -0,0 +1,110 @@
+// Package deadline reads the time limit a client puts on its request.
+package deadline
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Header carries a Go duration such as 1.5s, or a number of seconds.
+	Header = "X-Request-Timeout"
+	// GRPCHeader carries a timeout in the gRPC wire format, e.g. 100m.
+	GRPCHeader = "Grpc-Timeout"
+)
+
+// FromHeader returns the timeout requested in h, if any. X-Request-Timeout
+// wins over grpc-timeout.
+func FromHeader(h http.Header) (time.Duration, bool, error) {
+	if value := h.Get(Header); value != "" {
+		d, err := Parse(value)
+		if err != nil {
+			return 0, false, err
+		}
+		return d, true, nil
+	}
+
+	if value := h.Get(GRPCHeader); value != "" {
+		d, err := ParseGRPC(value)
+		if err != nil {
+			return 0, false, err
+		}
+		return d, true, nil
+	}
+
+	return 0, false, nil
+}
+
+// Parse reads an X-Request-Timeout value.
+func Parse(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, serr := strconv.ParseFloat(value, 64)
+		if serr != nil || math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+			return 0, errors.Errorf("invalid %s %q, want e.g. 2s or 2", Header, value)
+		}
+		d = time.Duration(seconds * float64(time.Second))
+	}
+	if d <= 0 {
+		return 0, errors.Errorf("%s must be positive, got %q", Header, value)
+	}
+	return d, nil
+}
+
+var grpcUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// ParseGRPC reads a grpc-timeout value: at most eight digits followed by
+// one of the units H, M, S, m, u or n.
+func ParseGRPC(value string) (time.Duration, error) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, errors.Errorf("invalid grpc-timeout %q", value)
+	}
+
+	unit, ok := grpcUnits[value[len(value)-1]]
+	if !ok {
+		return 0, errors.Errorf("invalid grpc-timeout unit in %q", value)
+	}
+
+	n, err := strconv.ParseUint(value[:len(value)-1], 10, 64)
+	if err != nil || n == 0 {
+		return 0, errors.Errorf("invalid grpc-timeout %q", value)
+	}
+
+	if n > uint64(math.MaxInt64/unit) {
+		return math.MaxInt64, nil
+	}
+	return time.Duration(n) * unit, nil
+}
+
+type callerKey struct{}
+
+// WithCaller records whether the deadline of ctx is one the client asked
+// for, shorter than the gateway would allow. Running out of such a
+// deadline says nothing about the backend.
+func WithCaller(ctx context.Context, caller bool) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// FromCaller reports whether the deadline of ctx was set by the client.
+func FromCaller(ctx context.Context) bool {
+	caller, _ := ctx.Value(callerKey{}).(bool)
+	return caller
+}
+
+// CallerExpired reports whether a call failed only because the deadline
+// the client chose ran out.
+func CallerExpired(ctx context.Context) bool {
+	return FromCaller(ctx) && errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
@@ -0,0 +1,215 @@
+// Package dynamic exposes backend RPCs that the gateway was not compiled
+// with. Their descriptors are read from a FileDescriptorSet file, as
+// written by protoc --descriptor_set_out --include_imports, or fetched
+// through gRPC server reflection, and their messages are dynamicpb
+// messages.
+package dynamic
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"api-gateway/config"
+	"api-gateway/pkg/transcode"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Route is a configured HTTP route and the backend that serves it.
+type Route struct {
+	Backend string
+	Binding transcode.Binding
+}
+
+// Routes resolves DYNAMIC_ROUTES against the descriptor set, the services
+// of the backends listed in DYNAMIC_REFLECTION and, failing those, the
+// services compiled into the gateway. Reflection
+// requests go over conns, keyed by backend name, and must finish within
+// TIMEOUT_DEFAULT.
+func Routes(ctx context.Context, cfg *config.Config, conns map[string]*grpc.ClientConn) ([]Route, error) {
+	if len(cfg.DYNAMIC_ROUTES) == 0 {
+		return nil, nil
+	}
+
+	files := new(protoregistry.Files)
+	backends := map[protoreflect.FullName]string{}
+	for service, backend := range cfg.SERVICE_BACKENDS {
+		backends[protoreflect.FullName(service)] = backend
+	}
+
+	if cfg.DYNAMIC_DESCRIPTOR_SET != "" {
+		set, err := ReadFile(cfg.DYNAMIC_DESCRIPTOR_SET)
+		if err != nil {
+			return nil, err
+		}
+		err = register(files, set.GetFile())
+		if err != nil {
+			return nil, errors.Wrap(err, cfg.DYNAMIC_DESCRIPTOR_SET)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.TIMEOUT_DEFAULT)
+	defer cancel()
+
+	for _, backend := range cfg.DYNAMIC_REFLECTION {
+		services, protos, err := Reflect(ctx, conns[backend])
+		if err != nil {
+			return nil, errors.Wrapf(err, "reflection on the %s service failed", backend)
+		}
+		err = register(files, protos)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reflection on the %s service", backend)
+		}
+		for _, service := range services {
+			backends[service] = backend
+		}
+	}
+
+	var routes []Route
+	for key, target := range cfg.DYNAMIC_ROUTES {
+		route, err := resolve(files, backends, key, target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "dynamic route %q", key)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// ReadFile reads a binary FileDescriptorSet.
+func ReadFile(path string) (*descriptorpb.FileDescriptorSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read descriptor set")
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	err = proto.Unmarshal(data, set)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse descriptor set %s", path)
+	}
+	return set, nil
+}
+
+// resolve turns a route such as "GET /v2/things/{thing_id}" with target
+// "item.ThingService/GetThing" into a binding. Requests of methods that
+// carry a body are read from it whole, the others from the query string.
+func resolve(files *protoregistry.Files, backends map[protoreflect.FullName]string, key, target string) (Route, error) {
+	method, path, ok := transcode.ParseRoute(key)
+	if !ok {
+		return Route{}, errors.New(`route must look like "GET /v2/things/{thing_id}"`)
+	}
+	service, name, ok := transcode.ParseTarget(target)
+	if !ok {
+		return Route{}, errors.Errorf(`method %q must look like "package.Service/Method"`, target)
+	}
+
+	d, err := resolver{files}.FindDescriptorByName(service)
+	if err != nil {
+		return Route{}, errors.Errorf("unknown service %s", service)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return Route{}, errors.Errorf("%s is not a service", service)
+	}
+	md := sd.Methods().ByName(name)
+	if md == nil {
+		return Route{}, errors.Errorf("service %s has no method %s", service, name)
+	}
+
+	backend, ok := backends[service]
+	if !ok {
+		return Route{}, errors.Errorf("no backend serves %s; add it to backends.services", service)
+	}
+
+	body := ""
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		body = "*"
+	}
+
+	b, err := transcode.NewBinding(md, method, path, body, "")
+	if err != nil {
+		return Route{}, err
+	}
+	return Route{Backend: backend, Binding: b}, nil
+}
+
+// register adds the files to the registry, dependencies first. They take
+// precedence over the files compiled into the gateway, which only serve
+// imports that are not among them, like google/api/annotations.proto.
+// Files already in the registry are kept.
+func register(files *protoregistry.Files, protos []*descriptorpb.FileDescriptorProto) error {
+	pending := map[string]*descriptorpb.FileDescriptorProto{}
+	for _, fd := range protos {
+		pending[fd.GetName()] = fd
+	}
+	resolver := resolver{files}
+
+	var add func(name string) error
+	add = func(name string) error {
+		_, err := files.FindFileByPath(name)
+		if err == nil {
+			return nil
+		}
+		fd, ok := pending[name]
+		if !ok {
+			_, err := protoregistry.GlobalFiles.FindFileByPath(name)
+			if err != nil {
+				return errors.Errorf("missing descriptor of %s", name)
+			}
+			return nil
+		}
+		delete(pending, name)
+
+		for _, dep := range fd.GetDependency() {
+			err := add(dep)
+			if err != nil {
+				return err
+			}
+		}
+
+		file, err := protodesc.NewFile(fd, resolver)
+		if err != nil {
+			return errors.Wrapf(err, "invalid descriptor of %s", name)
+		}
+		return files.RegisterFile(file)
+	}
+
+	for _, fd := range protos {
+		err := add(fd.GetName())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolver looks files up in the registry, then in the files compiled into
+// the gateway.
+type resolver struct {
+	files *protoregistry.Files
+}
+
+func (r resolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	fd, err := r.files.FindFileByPath(path)
+	if err == nil {
+		return fd, nil
+	}
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (r resolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	d, err := r.files.FindDescriptorByName(name)
+	if err == nil {
+		return d, nil
+	}
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
@@ -0,0 +1,114 @@
+package dynamic
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Reflect lists the services of a backend with the v1 server reflection
+// API and fetches the files that declare them, with their imports. The
+// reflection and health services themselves are left out.
+func Reflect(ctx context.Context, conn grpc.ClientConnInterface) ([]protoreflect.FullName, []*descriptorpb.FileDescriptorProto, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer stream.CloseSend()
+
+	res, err := ask(stream, &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var services []protoreflect.FullName
+	for _, s := range res.GetListServicesResponse().GetService() {
+		name := s.GetName()
+		if strings.HasPrefix(name, "grpc.reflection.") || strings.HasPrefix(name, "grpc.health.") {
+			continue
+		}
+		services = append(services, protoreflect.FullName(name))
+	}
+
+	var protos []*descriptorpb.FileDescriptorProto
+	seen := map[string]bool{}
+	collect := func(res *rpb.ServerReflectionResponse) error {
+		for _, data := range res.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			err := proto.Unmarshal(data, fd)
+			if err != nil {
+				return errors.Wrap(err, "invalid file descriptor")
+			}
+			if !seen[fd.GetName()] {
+				seen[fd.GetName()] = true
+				protos = append(protos, fd)
+			}
+		}
+		return nil
+	}
+
+	for _, service := range services {
+		res, err := ask(stream, &rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: string(service)},
+		})
+		if err != nil {
+			return nil, nil, errors.Wrap(err, string(service))
+		}
+		err = collect(res)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Servers usually send the imports along; ask for the ones that are
+	// neither sent nor compiled into the gateway.
+	for i := 0; i < len(protos); i++ {
+		for _, dep := range protos[i].GetDependency() {
+			if seen[dep] {
+				continue
+			}
+			if _, err := protoregistry.GlobalFiles.FindFileByPath(dep); err == nil {
+				continue
+			}
+			res, err := ask(stream, &rpb.ServerReflectionRequest{
+				MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			})
+			if err != nil {
+				return nil, nil, errors.Wrap(err, dep)
+			}
+			err = collect(res)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return services, protos, nil
+}
+
+func ask(stream rpb.ServerReflection_ServerReflectionInfoClient, req *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error) {
+	err := stream.Send(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if e := res.GetErrorResponse(); e != nil {
+		return nil, errors.Errorf("reflection error %d: %s", e.GetErrorCode(), e.GetErrorMessage())
+	}
+	return res, nil
+}
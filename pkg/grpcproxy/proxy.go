@@ -0,0 +1,184 @@
+// Package grpcproxy forwards gRPC calls to the backends without decoding
+// them, so any method of a backend service can be called through the
+// gateway, including streaming ones.
+package grpcproxy
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"api-gateway/pkg/deadline"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Frame is one message of a call, still encoded.
+type Frame struct {
+	Data []byte
+}
+
+// Codec passes frames through as they are. It is named "proto" so that
+// backends see the usual content type.
+type Codec struct{}
+
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	f, ok := v.(*Frame)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "grpcproxy: cannot encode %T", v)
+	}
+	return f.Data, nil
+}
+
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	f, ok := v.(*Frame)
+	if !ok {
+		return status.Errorf(codes.Internal, "grpcproxy: cannot decode into %T", v)
+	}
+	f.Data = append(f.Data[:0], data...)
+	return nil
+}
+
+func (Codec) Name() string {
+	return "proto"
+}
+
+// Proxy routes calls to a backend by the full name of their service.
+type Proxy struct {
+	conns map[string]grpc.ClientConnInterface
+}
+
+// New returns a proxy for the services in conns, keyed by full service
+// name, e.g. "item.ItemService".
+func New(conns map[string]grpc.ClientConnInterface) *Proxy {
+	return &Proxy{conns: conns}
+}
+
+// Service returns the full service name of a method such as
+// "/item.ItemService/GetItem".
+func Service(fullMethod string) string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service
+}
+
+// Handler serves every call to a service the gateway does not implement
+// itself; register it with grpc.UnknownServiceHandler and use Codec for
+// the server. Incoming metadata is forwarded, except for the headers that
+// describe the connection to the gateway.
+func (p *Proxy) Handler(srv interface{}, stream grpc.ServerStream) error {
+	method, ok := grpc.MethodFromServerStream(stream)
+	if !ok {
+		return status.Error(codes.Internal, "grpcproxy: no method in stream context")
+	}
+
+	conn, ok := p.conns[Service(method)]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown service %s", Service(method))
+	}
+
+	// The only deadline on the call is the one the caller set, so running
+	// out of it is not held against the backend.
+	ctx, cancel := context.WithCancel(deadline.WithCaller(stream.Context(), true))
+	defer cancel()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	for _, key := range []string{":authority", "content-type", "user-agent", "te", "connection"} {
+		md.Delete(key)
+	}
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	desc := &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}
+	backend, err := conn.NewStream(ctx, desc, method, grpc.ForceCodec(Codec{}))
+	if err != nil {
+		return err
+	}
+
+	sent := make(chan error, 1)
+	go func() {
+		sent <- forwardRequests(stream, backend)
+	}()
+
+	received := make(chan error, 1)
+	go func() {
+		received <- forwardResponses(backend, stream)
+	}()
+
+	for {
+		select {
+		case err := <-sent:
+			if err != nil {
+				// The caller went away, sent garbage or was refused by an
+				// interceptor; stop the backend call as well.
+				cancel()
+				if _, ok := status.FromError(err); ok {
+					return err
+				}
+				return status.Errorf(codes.Canceled, "forwarding request: %v", err)
+			}
+		case err := <-received:
+			stream.SetTrailer(backend.Trailer())
+			if err != io.EOF {
+				return err
+			}
+			return nil
+		}
+	}
+}
+
+// forwardRequests copies messages from the caller to the backend and
+// half-closes the backend stream when the caller is done.
+func forwardRequests(from grpc.ServerStream, to grpc.ClientStream) error {
+	for {
+		f := &Frame{}
+		err := from.RecvMsg(f)
+		if err == io.EOF {
+			return to.CloseSend()
+		}
+		if err != nil {
+			return err
+		}
+
+		err = to.SendMsg(f)
+		if err == io.EOF {
+			// The backend ended the call; its status is read by
+			// forwardResponses.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// forwardResponses copies the headers and messages of the backend to the
+// caller. It returns io.EOF when the call succeeded and the backend status
+// otherwise.
+func forwardResponses(from grpc.ClientStream, to grpc.ServerStream) error {
+	header, err := from.Header()
+	if err != nil {
+		// The call failed before the backend sent any headers; RecvMsg
+		// reports its status.
+		return from.RecvMsg(&Frame{})
+	}
+	err = to.SendHeader(header)
+	if err != nil {
+		return err
+	}
+
+	for {
+		f := &Frame{}
+		err := from.RecvMsg(f)
+		if err != nil {
+			return err
+		}
+
+		err = to.SendMsg(f)
+		if err != nil {
+			return err
+		}
+	}
+}
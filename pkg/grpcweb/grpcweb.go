@@ -0,0 +1,155 @@
+// Package grpcweb lets browsers call gRPC methods. It turns gRPC-Web
+// requests, which work over HTTP/1.1 and carry the trailers in the body,
+// into gRPC requests for a handler such as grpc.Server.ServeHTTP.
+package grpcweb
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	contentType     = "application/grpc-web"
+	contentTypeText = "application/grpc-web-text"
+
+	// trailerFlag marks the frame that carries the trailers.
+	trailerFlag = 0x80
+)
+
+// IsGRPCWeb reports whether r is a gRPC-Web request.
+func IsGRPCWeb(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), contentType)
+}
+
+// Handler serves gRPC-Web requests with next, which must speak gRPC.
+// Both the binary and the base64 text format are supported; streaming is
+// limited to server streams, as in browsers.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ct := r.Header.Get("Content-Type")
+		text := strings.HasPrefix(ct, contentTypeText)
+		subtype := strings.TrimPrefix(strings.TrimPrefix(ct, contentTypeText), contentType)
+
+		req := r.Clone(r.Context())
+		req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+		req.Header.Set("Content-Type", "application/grpc"+subtype)
+		req.Header.Del("Content-Length")
+		req.ContentLength = -1
+		if text {
+			req.Body = io.NopCloser(base64.NewDecoder(base64.StdEncoding, r.Body))
+		}
+
+		rw := &responseWriter{
+			w:           w,
+			header:      http.Header{},
+			text:        text,
+			contentType: contentType + subtype,
+		}
+		if text {
+			rw.contentType = contentTypeText + subtype
+		}
+
+		next.ServeHTTP(rw, req)
+		rw.finish()
+	})
+}
+
+// responseWriter sends the response of a gRPC handler in the gRPC-Web
+// format: the trailers the handler sets after writing the body are sent
+// as a last frame.
+type responseWriter struct {
+	w           http.ResponseWriter
+	header      http.Header
+	wroteHeader bool
+	text        bool
+	contentType string
+}
+
+func (rw *responseWriter) Header() http.Header {
+	return rw.header
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.wroteHeader = true
+
+	h := rw.w.Header()
+	for key, values := range rw.header {
+		if key == "Trailer" || strings.HasPrefix(key, http.TrailerPrefix) {
+			continue
+		}
+		h[key] = values
+	}
+	h.Set("Content-Type", rw.contentType)
+	h.Del("Content-Length")
+
+	rw.w.WriteHeader(code)
+}
+
+func (rw *responseWriter) Write(data []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if rw.text {
+		// Each write is encoded on its own, padding included, so that
+		// streamed messages can be decoded as they arrive.
+		_, err := io.WriteString(rw.w, base64.StdEncoding.EncodeToString(data))
+		return len(data), err
+	}
+	return rw.w.Write(data)
+}
+
+func (rw *responseWriter) Flush() {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if f, ok := rw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes the trailers: those declared in the Trailer header and
+// those set with http.TrailerPrefix.
+func (rw *responseWriter) finish() {
+	trailers := http.Header{}
+	for _, declared := range rw.header.Values("Trailer") {
+		for _, key := range strings.Split(declared, ",") {
+			key = http.CanonicalHeaderKey(strings.TrimSpace(key))
+			if values, ok := rw.header[key]; ok {
+				trailers[key] = values
+			}
+		}
+	}
+	for key, values := range rw.header {
+		if strings.HasPrefix(key, http.TrailerPrefix) {
+			trailers[http.CanonicalHeaderKey(strings.TrimPrefix(key, http.TrailerPrefix))] = values
+		}
+	}
+
+	keys := make([]string, 0, len(trailers))
+	for key := range trailers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var block strings.Builder
+	for _, key := range keys {
+		for _, value := range trailers[key] {
+			block.WriteString(strings.ToLower(key) + ": " + value + "\r\n")
+		}
+	}
+
+	frame := make([]byte, 5, 5+block.Len())
+	frame[0] = trailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(block.Len()))
+	frame = append(frame, block.String()...)
+
+	rw.Write(frame)
+	rw.Flush()
+}
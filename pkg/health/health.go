@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusDraining    = "draining"
+)
+
+type Dependency struct {
+	Status     string `json:"status"`
+	Connection string `json:"connection"`
+	Health     string `json:"health"`
+	Error      string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status       string                `json:"status"`
+	Dependencies map[string]Dependency `json:"dependencies"`
+}
+
+// Checker probes backends through their gRPC connection state and the
+// standard grpc.health.v1 Health service.
+type Checker struct {
+	conns    map[string]*grpc.ClientConn
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewChecker(conns map[string]*grpc.ClientConn, timeout time.Duration) *Checker {
+	return &Checker{conns: conns, timeout: timeout}
+}
+
+// Drain makes every following check fail, so that load balancers stop
+// sending traffic before the server shuts down.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Dependencies: map[string]Dependency{}}
+	if c.draining.Load() {
+		report.Status = StatusDraining
+		return report
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, conn := range c.conns {
+		wg.Add(1)
+		go func(name string, conn *grpc.ClientConn) {
+			defer wg.Done()
+
+			dep := c.check(ctx, conn)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Dependencies[name] = dep
+			if dep.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}(name, conn)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// Bypass leaves the probes of a Checker out of a client interceptor, so
+// that probes are neither failed fast nor counted by the circuit breaker,
+// retried, or recorded as backend calls.
+func Bypass(interceptor grpc.UnaryClientInterceptor) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if method == healthpb.Health_Check_FullMethodName {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return interceptor(ctx, method, req, reply, cc, invoker, opts...)
+	}
+}
+
+func (c *Checker) check(ctx context.Context, conn *grpc.ClientConn) Dependency {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	dep := Dependency{Status: StatusUnavailable}
+
+	res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	state := conn.GetState()
+	dep.Connection = state.String()
+
+	switch {
+	case err == nil:
+		dep.Health = res.GetStatus().String()
+		if res.GetStatus() == healthpb.HealthCheckResponse_SERVING {
+			dep.Status = StatusOK
+		}
+	case status.Code(err) == codes.Unimplemented:
+		// Backends without the Health service are judged by the connection.
+		dep.Health = "UNIMPLEMENTED"
+		if state == connectivity.Ready {
+			dep.Status = StatusOK
+		}
+	default:
+		dep.Health = "UNKNOWN"
+		dep.Error = status.Convert(err).Message()
+	}
+
+	return dep
+}
@@ -0,0 +1,34 @@
+package health
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestBypassSkipsProbes(t *testing.T) {
+	intercepted := 0
+	interceptor := Bypass(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		intercepted++
+		return invoker(ctx, method, req, reply, cc, opts...)
+	})
+
+	invoked := 0
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		invoked++
+		return nil
+	}
+
+	for _, method := range []string{healthpb.Health_Check_FullMethodName, "/item.ItemService/GetItem"} {
+		err := interceptor(context.Background(), method, nil, nil, nil, invoker)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if invoked != 2 || intercepted != 1 {
+		t.Errorf("invoked %d calls and intercepted %d, want 2 and 1", invoked, intercepted)
+	}
+}
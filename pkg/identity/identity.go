@@ -0,0 +1,61 @@
+package identity
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	UserIDHeader = "x-user-id"
+	RolesHeader  = "x-user-roles"
+)
+
+// Identity is the authenticated caller taken from a verified access token.
+type Identity struct {
+	UserID string
+	Roles  []string
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(contextKey{}).(Identity)
+	return id, ok
+}
+
+// UnaryClientInterceptor forwards the caller identity found in the context
+// to the backend as gRPC metadata, replacing anything set before.
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(outgoing(ctx), method, req, reply, cc, opts...)
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streams.
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(outgoing(ctx), desc, cc, method, opts...)
+}
+
+func outgoing(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Delete(UserIDHeader)
+	md.Delete(RolesHeader)
+
+	id, ok := FromContext(ctx)
+	if ok {
+		md.Set(UserIDHeader, id.UserID)
+		if len(id.Roles) > 0 {
+			md.Set(RolesHeader, strings.Join(id.Roles, ","))
+		}
+	}
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
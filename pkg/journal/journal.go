@@ -0,0 +1,150 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"api-gateway/pkg/logger"
+
+	"github.com/pkg/errors"
+)
+
+// maxLineSize bounds a single journal line when reading.
+const maxLineSize = 16 << 20
+
+// Entry is one journaled request and the response the gateway sent. A
+// JSON request body is kept in Body, any other in BodyRaw (base64 in the
+// journal). BodyRedacted marks bodies that sanitizing changed, which can
+// no longer be sent as they were. Responses beyond the body limit are
+// left out and marked with ResponseTruncated.
+type Entry struct {
+	Time              time.Time         `json:"time"`
+	RequestID         string            `json:"request_id,omitempty"`
+	Method            string            `json:"method"`
+	Route             string            `json:"route"`
+	Path              string            `json:"path"`
+	Headers           map[string]string `json:"headers,omitempty"`
+	Body              json.RawMessage   `json:"body,omitempty"`
+	BodyRaw           []byte            `json:"body_raw,omitempty"`
+	BodyTruncated     bool              `json:"body_truncated,omitempty"`
+	BodyRedacted      bool              `json:"body_redacted,omitempty"`
+	Status            int               `json:"status"`
+	Response          json.RawMessage   `json:"response,omitempty"`
+	ResponseTruncated bool              `json:"response_truncated,omitempty"`
+	LatencyMS         float64           `json:"latency_ms"`
+}
+
+// Writer appends entries to w as JSON lines.
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Write(e Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode journal entry")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err = w.w.Write(append(b, '\n'))
+	return errors.Wrap(err, "failed to write journal entry")
+}
+
+// Read calls fn for every entry in r, stopping at the first error.
+func Read(r io.Reader, fn func(Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var e Entry
+		err := json.Unmarshal(scanner.Bytes(), &e)
+		if err != nil {
+			return errors.Wrapf(err, "invalid journal entry on line %d", line)
+		}
+
+		err = fn(e)
+		if err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(scanner.Err(), "failed to read journal")
+}
+
+// Sanitize prepares a body for the journal. JSON bodies keep their shape
+// with the values of sensitive keys replaced and emails and tokens
+// masked; anything else is stored as a masked JSON string.
+func Sanitize(body []byte) json.RawMessage {
+	js, raw, _ := SanitizeRequest(body)
+	if raw != nil {
+		b, _ := json.Marshal(string(raw))
+		return b
+	}
+	return js
+}
+
+// SanitizeRequest is Sanitize for request bodies, which are replayed:
+// bodies that are not JSON are returned as masked bytes rather than as a
+// JSON string, and redacted reports whether anything was replaced.
+func SanitizeRequest(body []byte) (js json.RawMessage, raw []byte, redacted bool) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil, false
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v interface{}
+	err := dec.Decode(&v)
+	if err != nil || dec.More() {
+		masked := logger.Redact(string(body))
+		return nil, []byte(masked), masked != string(body)
+	}
+
+	b, err := json.Marshal(sanitize(v, &redacted))
+	if err != nil {
+		return nil, nil, true
+	}
+	return b, nil, redacted
+}
+
+func sanitize(v interface{}, redacted *bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if logger.IsSensitiveKey(key) {
+				v[key] = "[REDACTED]"
+				*redacted = true
+				continue
+			}
+			v[key] = sanitize(val, redacted)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = sanitize(val, redacted)
+		}
+	case string:
+		masked := logger.Redact(v)
+		if masked != v {
+			*redacted = true
+		}
+		return masked
+	}
+	return v
+}
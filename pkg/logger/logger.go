@@ -1,23 +1,147 @@
 package logger
 
 import (
-	"log"
+	"context"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
+
+	"api-gateway/config"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	OutputStdout = "stdout"
+	OutputFile   = "file"
 )
 
-func NewLogger() *slog.Logger {
-	opts := &slog.HandlerOptions{
-		Level: slog.LevelDebug,
+// Logger is the application logger. Its level can be changed at runtime
+// through Level, and every record passes the redaction handler before it
+// is written. Access is where the access log goes, and Journal where
+// requests are journaled; Journal is nil unless journaling is enabled.
+type Logger struct {
+	*slog.Logger
+	Level   *slog.LevelVar
+	Access  io.Writer
+	Journal io.Writer
+
+	closers []io.Closer
+	// configured is the level last read from the config, so that a reload
+	// does not undo a level set through the admin API unless it changed.
+	configured string
+}
+
+func NewLogger(cfg *config.Config) (*Logger, error) {
+	level := new(slog.LevelVar)
+	err := level.UnmarshalText([]byte(cfg.LOG_LEVEL))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid log level %q", cfg.LOG_LEVEL)
+	}
+
+	l := Logger{Level: level, configured: cfg.LOG_LEVEL}
+
+	out, err := l.open(cfg, cfg.LOG_OUTPUT, cfg.LOG_FILE)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch strings.ToLower(cfg.LOG_FORMAT) {
+	case FormatText, "":
+		handler = slog.NewTextHandler(out, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(out, opts)
+	default:
+		l.Close()
+		return nil, errors.Errorf("unknown log format %q", cfg.LOG_FORMAT)
 	}
 
-	file, err := os.OpenFile("app.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	l.Logger = slog.New(NewRedactHandler(handler))
+
+	l.Access, err = l.open(cfg, cfg.ACCESS_LOG_OUTPUT, cfg.ACCESS_LOG_FILE)
 	if err != nil {
-		log.Fatalf("error opening file: %v", err)
+		l.Close()
+		return nil, err
+	}
+
+	if cfg.JOURNAL_ENABLED {
+		l.Journal, err = l.open(cfg, OutputFile, cfg.JOURNAL_FILE)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+
+	return &l, nil
+}
+
+// Reload applies LOG_LEVEL from cfg if it changed. Formats and outputs are
+// fixed at startup.
+func (l *Logger) Reload(cfg *config.Config) error {
+	if cfg.LOG_LEVEL == l.configured {
 		return nil
 	}
 
-	logger := slog.New(slog.NewTextHandler(file, opts))
+	var level slog.Level
+	err := level.UnmarshalText([]byte(cfg.LOG_LEVEL))
+	if err != nil {
+		return errors.Wrapf(err, "invalid log level %q", cfg.LOG_LEVEL)
+	}
+
+	l.Level.Set(level)
+	l.configured = cfg.LOG_LEVEL
+	return nil
+}
+
+// Close closes the log files, if any.
+func (l *Logger) Close() error {
+	var first error
+	for _, c := range l.closers {
+		err := c.Close()
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	l.closers = nil
+	return first
+}
+
+// open returns stdout or a rotating file, depending on output.
+func (l *Logger) open(cfg *config.Config, output, path string) (io.Writer, error) {
+	switch strings.ToLower(output) {
+	case OutputStdout, "":
+		return os.Stdout, nil
+	case OutputFile:
+		file, err := OpenRotatingFile(path, RotateOptions{
+			MaxSize:    int64(cfg.LOG_MAX_SIZE_MB) << 20,
+			Interval:   cfg.LOG_ROTATE_INTERVAL,
+			MaxBackups: cfg.LOG_MAX_BACKUPS,
+			MaxAge:     cfg.LOG_MAX_AGE,
+		})
+		if err != nil {
+			return nil, err
+		}
+		l.closers = append(l.closers, file)
+		return file, nil
+	}
+	return nil, errors.Errorf("unknown log output %q", output)
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying a request-scoped logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
 
-	return logger
-}
\ No newline at end of file
+func FromContext(ctx context.Context) (*slog.Logger, bool) {
+	logger, ok := ctx.Value(contextKey{}).(*slog.Logger)
+	return logger, ok
+}
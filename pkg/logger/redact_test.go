@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRedactURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"/v1/items/42", "/v1/items/42"},
+		{"/v1/items?page=2&limit=10", "/v1/items?page=2&limit=10"},
+		{"/ws?access_token=eyJhbGciOi.abc.def&page=2", "/ws?access_token=[REDACTED]&page=2"},
+		{"/v1/items?api_key=k1&API_KEY=k2&token", "/v1/items?api_key=[REDACTED]&API_KEY=[REDACTED]&token"},
+		{"/v1/items?refresh%5Ftoken=abc", "/v1/items?refresh%5Ftoken=[REDACTED]"},
+	}
+	for _, tt := range tests {
+		u, err := url.ParseRequestURI(tt.uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := RedactURI(u); got != tt.want {
+			t.Errorf("RedactURI(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
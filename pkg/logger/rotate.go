@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const backupTimeFormat = "20060102T150405.000"
+
+// RotateOptions controls when a RotatingFile is rotated and how many old
+// files are kept. Zero values disable the respective limit.
+type RotateOptions struct {
+	// MaxSize is the size in bytes a file may grow to before rotation.
+	MaxSize int64
+	// Interval is how long a file is written to before rotation.
+	Interval time.Duration
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups int
+	// MaxAge is how long rotated files are kept.
+	MaxAge time.Duration
+}
+
+// RotatingFile is an io.Writer that appends to a file and moves it aside
+// as app-<timestamp>.log once it gets too big or too old, deleting backups
+// beyond the retention limits.
+type RotatingFile struct {
+	path string
+	opts RotateOptions
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func OpenRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, opts: opts}
+
+	err := os.MkdirAll(filepath.Dir(path), 0750)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create log directory")
+	}
+
+	err = f.open()
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	var rotateErr error
+	if f.size > 0 && f.due(int64(len(p))) {
+		rotateErr = f.rotate()
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, rotateErr
+}
+
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) due(next int64) bool {
+	if f.opts.MaxSize > 0 && f.size+next > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.Interval > 0 && time.Since(f.openedAt) >= f.opts.Interval
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return errors.Wrap(err, "failed to open log file")
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Wrap(err, "failed to stat log file")
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	return nil
+}
+
+// rotate moves the file aside and switches to a new one. The current file
+// stays open until the new one is, so a failed rotation leaves Write
+// appending to it and is retried on the next write.
+func (f *RotatingFile) rotate() error {
+	prefix, ext := f.backupPattern()
+	err := os.Rename(f.path, prefix+time.Now().Format(backupTimeFormat)+ext)
+	// The file is gone if it was moved by someone else or if the previous
+	// rotation failed to open its successor; either way, a new one is due.
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to rotate log file")
+	}
+
+	old := f.file
+	err = f.open()
+	if err != nil {
+		return err
+	}
+	err = old.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to close rotated log file")
+	}
+
+	f.prune()
+	return nil
+}
+
+// prune removes the backups beyond MaxBackups and those older than MaxAge.
+// Failures are ignored; they are retried on the next rotation.
+func (f *RotatingFile) prune() {
+	if f.opts.MaxBackups <= 0 && f.opts.MaxAge <= 0 {
+		return
+	}
+
+	prefix, ext := f.backupPattern()
+	backups, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return
+	}
+
+	// The timestamp in the name makes lexical order chronological.
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	for i, backup := range backups {
+		_, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(backup, prefix), ext))
+		if err != nil {
+			continue
+		}
+
+		expired := f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups
+		if !expired && f.opts.MaxAge > 0 {
+			info, err := os.Stat(backup)
+			expired = err == nil && time.Since(info.ModTime()) > f.opts.MaxAge
+		}
+		if expired {
+			os.Remove(backup)
+		}
+	}
+}
+
+// backupPattern splits "logs/app.log" into "logs/app-" and ".log".
+func (f *RotatingFile) backupPattern() (string, string) {
+	ext := filepath.Ext(f.path)
+	return strings.TrimSuffix(f.path, ext) + "-", ext
+}
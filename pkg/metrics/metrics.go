@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"api-gateway/pkg/stream"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "api_gateway"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by route template, method and status.",
+	}, []string{"route", "method", "status"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time spent handling HTTP requests, by route template, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	HTTPInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests currently being handled, by route template and method.",
+	}, []string{"route", "method"})
+
+	GRPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_requests_total",
+		Help:      "Unary calls made to backends, by backend, service, method and status code.",
+	}, []string{"backend", "service", "method", "code"})
+
+	GRPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_request_duration_seconds",
+		Help:      "Time spent on unary calls to backends, including retries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"backend", "service", "method", "code"})
+
+	GRPCInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_requests_in_flight",
+		Help:      "Unary calls to backends that have not returned yet.",
+	}, []string{"backend", "service", "method"})
+
+	GRPCStreams = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_streams_total",
+		Help:      "Streaming calls made to backends, by backend, service, method and status code.",
+	}, []string{"backend", "service", "method", "code"})
+
+	GRPCStreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_stream_duration_seconds",
+		Help:      "Time from opening a stream to a backend to its final status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"backend", "service", "method", "code"})
+
+	GRPCStreamsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_streams_in_flight",
+		Help:      "Streams to backends that have not ended yet.",
+	}, []string{"backend", "service", "method"})
+
+	ConfigReloads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "config_reloads_total",
+		Help:      "Config reloads, by result (success or failure).",
+	}, []string{"result"})
+
+	ConfigLastReload = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "config_last_reload_success_timestamp_seconds",
+		Help:      "Time of the last successful config reload.",
+	})
+)
+
+// UnaryClientInterceptor records count, latency and in-flight calls for
+// the named backend. Calls rejected by the circuit breaker are counted too,
+// as long as the interceptor runs before it.
+func UnaryClientInterceptor(backend string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		service, name := splitMethod(method)
+
+		inFlight := GRPCInFlight.WithLabelValues(backend, service, name)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		code := status.Code(err).String()
+		GRPCRequests.WithLabelValues(backend, service, name, code).Inc()
+		GRPCDuration.WithLabelValues(backend, service, name, code).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streams, which
+// are recorded in metrics of their own when they end.
+func StreamClientInterceptor(backend string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		service, name := splitMethod(method)
+
+		inFlight := GRPCStreamsInFlight.WithLabelValues(backend, service, name)
+		inFlight.Inc()
+
+		start := time.Now()
+		done := func(err error) {
+			inFlight.Dec()
+			code := status.Code(err).String()
+			GRPCStreams.WithLabelValues(backend, service, name, code).Inc()
+			GRPCStreamDuration.WithLabelValues(backend, service, name, code).Observe(time.Since(start).Seconds())
+		}
+
+		s, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			done(err)
+			return nil, err
+		}
+		return stream.Watch(ctx, s, done), nil
+	}
+}
+
+// splitMethod turns "/item.ItemService/GetItem" into "item.ItemService"
+// and "GetItem".
+func splitMethod(fullMethod string) (string, string) {
+	service, method, found := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !found {
+		return "unknown", service
+	}
+	return service, method
+}
@@ -0,0 +1,334 @@
+// Package openapi describes transcoded routes in Swagger 2.0, the format
+// of the swag documents, so that the routes generated from google.api.http
+// annotations are documented from the same annotations.
+package openapi
+
+import (
+	"api-gateway/pkg/transcode"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Options control how routes are described.
+type Options struct {
+	// UseProtoNames names JSON fields as in the protos instead of in
+	// lowerCamelCase, like the protojson option of the same name.
+	UseProtoNames bool
+	// Public reports the methods that can be called without credentials.
+	Public func(protoreflect.FullName) bool
+	// Security names the security definition the other methods require.
+	Security string
+	// Error is the definition of the error body, if there is one.
+	Error string
+}
+
+// Document holds the paths and definitions of a Swagger document.
+type Document struct {
+	Paths       map[string]map[string]*Operation `json:"paths"`
+	Definitions map[string]*Schema               `json:"definitions"`
+}
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string   `json:"name"`
+	In       string   `json:"in"`
+	Required bool     `json:"required,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Format   string   `json:"format,omitempty"`
+	Enum     []string `json:"enum,omitempty"`
+	Items    *Schema  `json:"items,omitempty"`
+	Schema   *Schema  `json:"schema,omitempty"`
+}
+
+type Response struct {
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Build documents the bindings. Paths are the templates of the bindings,
+// custom verb included, with a parameter per variable.
+func Build(bindings []transcode.Binding, opts Options) *Document {
+	b := builder{
+		opts: opts,
+		doc: &Document{
+			Paths:       map[string]map[string]*Operation{},
+			Definitions: map[string]*Schema{},
+		},
+		ids: map[string]int{},
+	}
+	for _, binding := range bindings {
+		b.add(binding)
+	}
+	return b.doc
+}
+
+type builder struct {
+	opts Options
+	doc  *Document
+	ids  map[string]int
+}
+
+func (b *builder) add(binding transcode.Binding) {
+	md := binding.Method
+	service := md.Parent().(protoreflect.ServiceDescriptor)
+
+	op := &Operation{
+		Tags:        []string{string(service.Name())},
+		Summary:     string(md.Name()),
+		OperationID: b.operationID(string(service.Name()) + "_" + string(md.Name())),
+		Responses: map[string]*Response{
+			"200": {Description: "OK", Schema: b.responseSchema(md.Output(), binding.ResponseBody)},
+		},
+	}
+	if b.opts.Error != "" {
+		op.Responses["default"] = &Response{Description: "Error", Schema: ref(b.opts.Error)}
+	}
+	if b.opts.Security != "" && (b.opts.Public == nil || !b.opts.Public(md.FullName())) {
+		op.Security = []map[string][]string{{b.opts.Security: {}}}
+	}
+
+	path, bound := b.path(binding.Template, md.Input(), op)
+	switch binding.Body {
+	case "*":
+		op.Parameters = append(op.Parameters, &Parameter{Name: "body", In: "body", Required: true, Schema: b.message(md.Input())})
+	case "":
+		op.Parameters = append(op.Parameters, b.query(md.Input(), bound)...)
+	default:
+		fd := md.Input().Fields().ByName(protoreflect.Name(binding.Body))
+		op.Parameters = append(op.Parameters, &Parameter{Name: b.jsonName(fd), In: "body", Required: true, Schema: b.field(fd)})
+	}
+
+	if b.doc.Paths[path] == nil {
+		b.doc.Paths[path] = map[string]*Operation{}
+	}
+	b.doc.Paths[path][strings.ToLower(binding.HTTPMethod)] = op
+}
+
+// operationID makes id unique, as operations must be, by numbering the
+// additional bindings of a method.
+func (b *builder) operationID(id string) string {
+	b.ids[id]++
+	if n := b.ids[id]; n > 1 {
+		return id + strconv.Itoa(n)
+	}
+	return id
+}
+
+// path writes the template with a path parameter per variable and per
+// wildcard outside of one, and returns the fields the path binds.
+func (b *builder) path(t *transcode.Template, input protoreflect.MessageDescriptor, op *Operation) (string, []string) {
+	var sb strings.Builder
+	var bound []string
+	for i := 0; i < len(t.Segments); i++ {
+		sb.WriteByte('/')
+		seg := t.Segments[i]
+
+		if v, ok := variableAt(t, i); ok {
+			sb.WriteString("{" + v.FieldPath + "}")
+			bound = append(bound, v.FieldPath)
+			p := &Parameter{Name: v.FieldPath, In: "path", Required: true, Type: "string"}
+			if fd := fieldByPath(input, v.FieldPath); fd != nil {
+				s := b.field(fd)
+				p.Type, p.Format, p.Enum = s.Type, s.Format, s.Enum
+			}
+			op.Parameters = append(op.Parameters, p)
+			i = v.End - 1
+			continue
+		}
+
+		if seg.Wildcard == "" {
+			sb.WriteString(seg.Literal)
+			continue
+		}
+		name := "_" + strconv.Itoa(i)
+		sb.WriteString("{" + name + "}")
+		op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Type: "string"})
+	}
+	if len(t.Segments) == 0 {
+		sb.WriteByte('/')
+	}
+	if t.Verb != "" {
+		sb.WriteString(":" + t.Verb)
+	}
+	return sb.String(), bound
+}
+
+func variableAt(t *transcode.Template, i int) (transcode.Variable, bool) {
+	for _, v := range t.Variables {
+		if v.Start == i {
+			return v, true
+		}
+	}
+	return transcode.Variable{}, false
+}
+
+// query lists the fields that can be set with query parameters: the
+// scalar fields that the path does not bind.
+func (b *builder) query(input protoreflect.MessageDescriptor, bound []string) []*Parameter {
+	var params []*Parameter
+	fields := input.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Message() != nil || fd.IsMap() || slices.Contains(bound, string(fd.Name())) {
+			continue
+		}
+
+		s := b.field(fd)
+		p := &Parameter{Name: b.jsonName(fd), In: "query", Type: s.Type, Format: s.Format, Enum: s.Enum}
+		if fd.IsList() {
+			p.Type, p.Format, p.Enum, p.Items = "array", "", nil, s.Items
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+func (b *builder) responseSchema(output protoreflect.MessageDescriptor, field string) *Schema {
+	if field == "" {
+		return b.message(output)
+	}
+	return b.field(output.Fields().ByName(protoreflect.Name(field)))
+}
+
+// message returns a reference to the definition of md, adding it and the
+// messages it refers to first.
+func (b *builder) message(md protoreflect.MessageDescriptor) *Schema {
+	if s, ok := wellKnown(md); ok {
+		return s
+	}
+
+	name := string(md.FullName())
+	if _, ok := b.doc.Definitions[name]; !ok {
+		def := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		b.doc.Definitions[name] = def
+
+		fields := md.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			def.Properties[b.jsonName(fd)] = b.field(fd)
+		}
+	}
+	return ref(name)
+}
+
+func (b *builder) field(fd protoreflect.FieldDescriptor) *Schema {
+	if fd.IsMap() {
+		return &Schema{Type: "object", AdditionalProperties: b.value(fd.MapValue())}
+	}
+	if fd.IsList() {
+		return &Schema{Type: "array", Items: b.value(fd)}
+	}
+	return b.value(fd)
+}
+
+// value describes a single value of fd the way protojson encodes it.
+func (b *builder) value(fd protoreflect.FieldDescriptor) *Schema {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return &Schema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return &Schema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &Schema{Type: "integer", Format: "int64"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return &Schema{Type: "string", Format: "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return &Schema{Type: "string", Format: "uint64"}
+	case protoreflect.FloatKind:
+		return &Schema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		return &Schema{Type: "number", Format: "double"}
+	case protoreflect.StringKind:
+		return &Schema{Type: "string"}
+	case protoreflect.BytesKind:
+		return &Schema{Type: "string", Format: "byte"}
+	case protoreflect.EnumKind:
+		s := &Schema{Type: "string"}
+		values := fd.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			s.Enum = append(s.Enum, string(values.Get(i).Name()))
+		}
+		return s
+	}
+	return b.message(fd.Message())
+}
+
+func (b *builder) jsonName(fd protoreflect.FieldDescriptor) string {
+	if b.opts.UseProtoNames {
+		return string(fd.Name())
+	}
+	return fd.JSONName()
+}
+
+// wellKnown describes the types that protojson encodes specially.
+func wellKnown(md protoreflect.MessageDescriptor) (*Schema, bool) {
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		return &Schema{Type: "string", Format: "date-time"}, true
+	case "google.protobuf.Duration", "google.protobuf.FieldMask",
+		"google.protobuf.StringValue":
+		return &Schema{Type: "string"}, true
+	case "google.protobuf.BytesValue":
+		return &Schema{Type: "string", Format: "byte"}, true
+	case "google.protobuf.BoolValue":
+		return &Schema{Type: "boolean"}, true
+	case "google.protobuf.Int32Value":
+		return &Schema{Type: "integer", Format: "int32"}, true
+	case "google.protobuf.UInt32Value":
+		return &Schema{Type: "integer", Format: "int64"}, true
+	case "google.protobuf.Int64Value":
+		return &Schema{Type: "string", Format: "int64"}, true
+	case "google.protobuf.UInt64Value":
+		return &Schema{Type: "string", Format: "uint64"}, true
+	case "google.protobuf.FloatValue":
+		return &Schema{Type: "number", Format: "float"}, true
+	case "google.protobuf.DoubleValue":
+		return &Schema{Type: "number", Format: "double"}, true
+	case "google.protobuf.Struct", "google.protobuf.Any", "google.protobuf.Empty":
+		return &Schema{Type: "object"}, true
+	case "google.protobuf.ListValue":
+		return &Schema{Type: "array", Items: &Schema{}}, true
+	case "google.protobuf.Value":
+		return &Schema{}, true
+	}
+	return nil, false
+}
+
+func fieldByPath(md protoreflect.MessageDescriptor, path string) protoreflect.FieldDescriptor {
+	for {
+		name, rest, nested := strings.Cut(path, ".")
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil || !nested {
+			return fd
+		}
+		if fd.Message() == nil {
+			return nil
+		}
+		md, path = fd.Message(), rest
+	}
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/definitions/" + name}
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Limit allows Requests per period, refilled continuously, with bursts of
+// up to Burst requests.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// ParseLimit reads limits written as "100/1m", optionally followed by a
+// burst size as in "100/1m:20". The burst defaults to the request count.
+func ParseLimit(s string) (Limit, error) {
+	rate, burst, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+
+	requests, per, found := strings.Cut(rate, "/")
+	if !found {
+		return Limit{}, errors.Errorf("invalid rate limit %q, want <requests>/<period>", s)
+	}
+
+	l := Limit{}
+	var err error
+	l.Requests, err = strconv.Atoi(requests)
+	if err != nil || l.Requests <= 0 {
+		return Limit{}, errors.Errorf("invalid request count in rate limit %q", s)
+	}
+
+	l.Per, err = time.ParseDuration(per)
+	if err != nil || l.Per <= 0 {
+		return Limit{}, errors.Errorf("invalid period in rate limit %q", s)
+	}
+
+	l.Burst = l.Requests
+	if hasBurst {
+		l.Burst, err = strconv.Atoi(burst)
+		if err != nil || l.Burst <= 0 {
+			return Limit{}, errors.Errorf("invalid burst in rate limit %q", s)
+		}
+	}
+
+	return l, nil
+}
+
+// rate returns the refill speed in tokens per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result describes the state of a bucket after a request was counted.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets. MemoryStore serves a single gateway instance;
+// a shared implementation lets several instances enforce one limit.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+type Limiter struct {
+	store Store
+	clock Clock
+}
+
+func NewLimiter(store Store, clock Clock) *Limiter {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &Limiter{store: store, clock: clock}
+}
+
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	return l.store.Take(ctx, key, limit, l.clock.Now())
+}
@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestLimiter() (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return NewLimiter(NewMemoryStore(), clock), clock
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Limit
+		err  bool
+	}{
+		{in: "100/1m", want: Limit{Requests: 100, Per: time.Minute, Burst: 100}},
+		{in: "10/1s:20", want: Limit{Requests: 10, Per: time.Second, Burst: 20}},
+		{in: " 5/1h ", want: Limit{Requests: 5, Per: time.Hour, Burst: 5}},
+		{in: "100", err: true},
+		{in: "0/1m", err: true},
+		{in: "10/0s", err: true},
+		{in: "10/minute", err: true},
+		{in: "10/1m:0", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseLimit(%q) = %+v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseLimit(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLimiterBurstThenRefill(t *testing.T) {
+	l, clock := newTestLimiter()
+	limit := Limit{Requests: 2, Per: time.Second, Burst: 2}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		res, err := l.Allow(ctx, "k", limit)
+		if err != nil || !res.Allowed {
+			t.Fatalf("request %d: got %+v, %v, want allowed", i, res, err)
+		}
+	}
+
+	res, err := l.Allow(ctx, "k", limit)
+	if err != nil || res.Allowed {
+		t.Fatalf("request over the burst: got %+v, %v, want denied", res, err)
+	}
+	if res.RetryAfter != 500*time.Millisecond {
+		t.Errorf("RetryAfter = %s, want 500ms", res.RetryAfter)
+	}
+	if res.Remaining != 0 {
+		t.Errorf("Remaining = %d, want 0", res.Remaining)
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	res, err = l.Allow(ctx, "k", limit)
+	if err != nil || !res.Allowed {
+		t.Fatalf("after refilling one token: got %+v, %v, want allowed", res, err)
+	}
+
+	clock.Advance(time.Hour)
+	res, err = l.Allow(ctx, "k", limit)
+	if err != nil || !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("after a full refill: got %+v, %v, want allowed with 1 remaining", res, err)
+	}
+	if res.Reset != 500*time.Millisecond {
+		t.Errorf("Reset = %s, want 500ms", res.Reset)
+	}
+}
+
+func TestLimiterKeysAreSeparate(t *testing.T) {
+	l, _ := newTestLimiter()
+	limit := Limit{Requests: 1, Per: time.Minute, Burst: 1}
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b"} {
+		res, _ := l.Allow(ctx, key, limit)
+		if !res.Allowed {
+			t.Errorf("first request for %q was denied", key)
+		}
+	}
+	res, _ := l.Allow(ctx, "a", limit)
+	if res.Allowed {
+		t.Error("second request for \"a\" was allowed")
+	}
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 1, Per: time.Second, Burst: 1}
+
+	s.Take(context.Background(), "old", limit, now)
+	s.Take(context.Background(), "new", limit, now.Add(2*sweepInterval))
+
+	if _, ok := s.buckets["old"]; ok {
+		t.Error("refilled bucket was not swept")
+	}
+	if _, ok := s.buckets["new"]; !ok {
+		t.Error("bucket in use was swept")
+	}
+}
@@ -0,0 +1,177 @@
+// Package reload applies config changes to a running gateway, on SIGHUP
+// and when the config file changes.
+package reload
+
+import (
+	"api-gateway/config"
+	"api-gateway/pkg/metrics"
+	"context"
+	"crypto/sha256"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Target is a component whose settings can change at runtime. Reload must
+// either apply cfg completely or leave the component as it was.
+type Target interface {
+	Reload(cfg *config.Config) error
+}
+
+// TargetFunc adapts a function to Target.
+type TargetFunc func(cfg *config.Config) error
+
+func (f TargetFunc) Reload(cfg *config.Config) error {
+	return f(cfg)
+}
+
+// Reloader loads the config again and hands the settings that can change
+// without a restart to the registered targets.
+type Reloader struct {
+	args    []string
+	logger  *slog.Logger
+	current atomic.Pointer[config.Config]
+
+	mu      sync.Mutex
+	targets []Target
+}
+
+// New returns a Reloader for the config loaded from args.
+func New(cfg *config.Config, args []string, logger *slog.Logger) *Reloader {
+	r := &Reloader{
+		args:   args,
+		logger: logger,
+	}
+	r.current.Store(cfg)
+	return r
+}
+
+// Register adds targets. They are reloaded in the order they are added.
+func (r *Reloader) Register(targets ...Target) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.targets = append(r.targets, targets...)
+}
+
+// Current returns the config in effect.
+func (r *Reloader) Current() *config.Config {
+	return r.current.Load()
+}
+
+// Reload loads and validates the config, then applies it to every target.
+// If a target fails, the ones already updated are rolled back to the
+// previous config and the error is returned.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.reload()
+	if err != nil {
+		metrics.ConfigReloads.WithLabelValues("failure").Inc()
+		r.logger.Error("config reload failed", "error", err)
+		return err
+	}
+
+	metrics.ConfigReloads.WithLabelValues("success").Inc()
+	metrics.ConfigLastReload.SetToCurrentTime()
+	return nil
+}
+
+func (r *Reloader) reload() error {
+	cur := r.current.Load()
+
+	loaded, shadowed, err := config.LoadShadowed(r.args)
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+	if len(shadowed) > 0 {
+		r.logger.Warn("config file settings are overridden by the environment or flags", "keys", shadowed)
+	}
+
+	next, ignored := cur.Reloadable(loaded)
+	if len(ignored) > 0 {
+		r.logger.Warn("changed settings need a restart to take effect", "keys", ignored)
+	}
+
+	changed := cur.Changed(next)
+	if len(changed) == 0 {
+		r.logger.Info("config reloaded, nothing changed")
+		return nil
+	}
+
+	for i, target := range r.targets {
+		err := target.Reload(next)
+		if err == nil {
+			continue
+		}
+
+		for _, applied := range r.targets[:i] {
+			rollbackErr := applied.Reload(cur)
+			if rollbackErr != nil {
+				r.logger.Error("failed to roll back config", "error", rollbackErr)
+			}
+		}
+		return errors.Wrap(err, "failed to apply config")
+	}
+
+	r.current.Store(next)
+	r.logger.Info("config reloaded", "changed", changed)
+	return nil
+}
+
+// Run reloads on SIGHUP and, if there is a config file and a watch
+// interval, whenever the file content changes. It returns when ctx is
+// cancelled.
+func (r *Reloader) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	path, err := config.FilePath(r.args)
+	if err != nil {
+		r.logger.Error("config file is not watched", "error", err)
+	}
+
+	var tick <-chan time.Time
+	interval := r.Current().CONFIG_WATCH_INTERVAL
+	if path != "" && interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	sum := checksum(path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.logger.Info("received SIGHUP, reloading config")
+			r.Reload()
+		case <-tick:
+			next := checksum(path)
+			if next == sum {
+				continue
+			}
+			sum = next
+			r.logger.Info("config file changed, reloading config", "file", path)
+			r.Reload()
+		}
+	}
+}
+
+// checksum hashes the file at path. A file that cannot be read hashes to
+// nothing, so that it counts as a change once it is back.
+func checksum(path string) [sha256.Size]byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}
+	}
+	return sha256.Sum256(data)
+}
@@ -0,0 +1,163 @@
+package resilience
+
+import (
+	"api-gateway/pkg/deadline"
+	"api-gateway/pkg/stream"
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Breaker stops calls to a backend after Threshold consecutive failures.
+// Once OpenTimeout has passed a single probe call is let through; its
+// outcome closes the breaker again or keeps it open.
+type Breaker struct {
+	name        string
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+type BreakerState struct {
+	Name     string    `json:"name"`
+	State    string    `json:"state"`
+	Failures int       `json:"failures"`
+	OpenedAt time.Time `json:"opened_at,omitempty"`
+}
+
+func NewBreaker(name string, threshold int, openTimeout time.Duration) *Breaker {
+	return &Breaker{name: name, threshold: threshold, openTimeout: openTimeout}
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) Snapshot() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := BreakerState{Name: b.name, State: b.state.String(), Failures: b.failures}
+	if b.state != Closed {
+		s.OpenedAt = b.openedAt
+	}
+	return s
+}
+
+// UnaryClientInterceptor fails fast with Unavailable while the breaker is open.
+func (b *Breaker) UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	probe, ok := b.allow()
+	if !ok {
+		return status.Errorf(codes.Unavailable, "circuit breaker for %s service is open", b.name)
+	}
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	b.record(ctx, probe, err)
+	return err
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streams. A stream
+// counts as a call that ends with its final status.
+func (b *Breaker) StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	probe, ok := b.allow()
+	if !ok {
+		return nil, status.Errorf(codes.Unavailable, "circuit breaker for %s service is open", b.name)
+	}
+
+	s, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		b.record(ctx, probe, err)
+		return nil, err
+	}
+	return stream.Watch(ctx, s, func(err error) {
+		b.record(ctx, probe, err)
+	}), nil
+}
+
+func (b *Breaker) allow() (probe bool, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false, false
+		}
+		b.state = HalfOpen
+		fallthrough
+	case HalfOpen:
+		if b.probing {
+			return false, false
+		}
+		b.probing = true
+		return true, true
+	}
+	return false, true
+}
+
+// record counts the outcome of a call made with ctx. Running out of time
+// counts against the backend only when the deadline was the gateway's;
+// one the client chose to shorten does not.
+func (b *Breaker) record(ctx context.Context, probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	code := status.Code(err)
+	if code == codes.DeadlineExceeded && deadline.CallerExpired(ctx) {
+		code = codes.Canceled
+	}
+
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		b.failures++
+		if probe || (b.state == Closed && b.failures >= b.threshold) {
+			b.state = Open
+			b.openedAt = time.Now()
+		}
+	case codes.Canceled:
+		// The caller gave up, which says nothing about the backend.
+	default:
+		// Calls started before the breaker opened must not close it.
+		if probe || b.state == Closed {
+			b.failures = 0
+			b.state = Closed
+		}
+	}
+}
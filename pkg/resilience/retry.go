@@ -0,0 +1,66 @@
+package resilience
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	RetryableCodes []codes.Code
+}
+
+// backoff returns a random wait in [0, min(MaxBackoff, InitialBackoff *
+// Multiplier^attempt)), the "full jitter" strategy, so that clients failing
+// together do not retry together.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	limit := float64(p.InitialBackoff)
+	for i := 0; i < attempt; i++ {
+		limit *= p.Multiplier
+		if limit >= float64(p.MaxBackoff) {
+			limit = float64(p.MaxBackoff)
+			break
+		}
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(limit)))
+}
+
+// UnaryRetryInterceptor retries calls to the methods that have a policy.
+// Only idempotent methods should be given one.
+func UnaryRetryInterceptor(policies map[string]RetryPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		policy, ok := policies[method]
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		for attempt := 0; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt+1 >= policy.MaxAttempts ||
+				!slices.Contains(policy.RetryableCodes, status.Code(err)) {
+				return err
+			}
+
+			timer := time.NewTimer(policy.backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package resilience
+
+import (
+	"api-gateway/pkg/deadline"
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Timeout caps calls to a backend, including retries. It can be changed
+// at runtime with Set.
+type Timeout struct {
+	d atomic.Int64
+}
+
+func NewTimeout(d time.Duration) *Timeout {
+	t := &Timeout{}
+	t.Set(d)
+	return t
+}
+
+// Set changes the cap; zero disables it.
+func (t *Timeout) Set(d time.Duration) {
+	t.d.Store(int64(d))
+}
+
+func (t *Timeout) Get() time.Duration {
+	return time.Duration(t.d.Load())
+}
+
+// UnaryClientInterceptor applies the cap. A shorter deadline already set
+// by the caller is kept; once the cap is the one that applies, running out
+// of time is the backend's fault again.
+func (t *Timeout) UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	timeout := t.Get()
+	if timeout <= 0 {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	current, ok := ctx.Deadline()
+	if ok && time.Until(current) <= timeout {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	ctx, cancel := context.WithTimeout(deadline.WithCaller(ctx, false), timeout)
+	defer cancel()
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
@@ -0,0 +1,63 @@
+// Package stream lets client interceptors act on the end of a stream,
+// which happens after the interceptor has returned.
+package stream
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Watch returns s with done called once with the final status of the
+// stream: nil when RecvMsg reports io.EOF, the error of RecvMsg or SendMsg
+// otherwise, or the error of ctx when the caller abandons the stream by
+// cancelling it.
+func Watch(ctx context.Context, s grpc.ClientStream, done func(err error)) grpc.ClientStream {
+	w := &watched{ClientStream: s, done: done}
+	w.stop = context.AfterFunc(ctx, func() {
+		w.finish(status.FromContextError(ctx.Err()).Err())
+	})
+	return w
+}
+
+type watched struct {
+	grpc.ClientStream
+	once sync.Once
+	stop func() bool
+	done func(err error)
+}
+
+func (w *watched) finish(err error) {
+	w.once.Do(func() {
+		w.done(err)
+	})
+}
+
+// end reports the status the stream ended with, unless cancelling ctx
+// already did.
+func (w *watched) end(err error) {
+	w.stop()
+	w.finish(err)
+}
+
+func (w *watched) RecvMsg(m interface{}) error {
+	err := w.ClientStream.RecvMsg(m)
+	if err == io.EOF {
+		w.end(nil)
+	} else if err != nil {
+		w.end(err)
+	}
+	return err
+}
+
+func (w *watched) SendMsg(m interface{}) error {
+	err := w.ClientStream.SendMsg(m)
+	if err != nil && err != io.EOF {
+		// io.EOF means the stream ended; RecvMsg reports how.
+		w.end(err)
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+// Package transcode reads the google.api.http annotations of gRPC methods
+// so that the gateway can serve them as HTTP/JSON routes.
+package transcode
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Binding is one HTTP mapping of a gRPC method.
+type Binding struct {
+	Method     protoreflect.MethodDescriptor
+	HTTPMethod string
+	Template   *Template
+	// Body is "*" when the whole request comes from the body, the name of
+	// a request field, or empty when there is no body.
+	Body string
+	// ResponseBody names the response field that is sent instead of the
+	// whole response, if any.
+	ResponseBody string
+}
+
+// Bindings returns the bindings declared on the unary methods of sd,
+// including additional bindings. Methods without annotations are skipped.
+func Bindings(sd protoreflect.ServiceDescriptor) ([]Binding, error) {
+	var bindings []Binding
+	for i := 0; i < sd.Methods().Len(); i++ {
+		md := sd.Methods().Get(i)
+
+		rule, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if !ok || rule == nil {
+			continue
+		}
+		rules := append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...)
+		for _, r := range rules {
+			b, err := binding(md, r)
+			if err != nil {
+				return nil, errors.Wrap(err, string(md.FullName()))
+			}
+			bindings = append(bindings, b)
+		}
+	}
+	return bindings, nil
+}
+
+func binding(md protoreflect.MethodDescriptor, rule *annotations.HttpRule) (Binding, error) {
+	var method, path string
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		method, path = http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		method, path = http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		method, path = http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		method, path = http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		method, path = http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		method, path = pattern.Custom.GetKind(), pattern.Custom.GetPath()
+	default:
+		return Binding{}, errors.New("http rule has no pattern")
+	}
+
+	return NewBinding(md, method, path, rule.GetBody(), rule.GetResponseBody())
+}
+
+// NewBinding binds md to an HTTP method and path template, checking that
+// the template variables and the body fields exist.
+func NewBinding(md protoreflect.MethodDescriptor, method, path, body, responseBody string) (Binding, error) {
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return Binding{}, errors.New("streaming methods cannot be transcoded")
+	}
+
+	template, err := ParseTemplate(path)
+	if err != nil {
+		return Binding{}, err
+	}
+
+	b := Binding{
+		Method:       md,
+		HTTPMethod:   method,
+		Template:     template,
+		Body:         body,
+		ResponseBody: responseBody,
+	}
+
+	for _, v := range b.Template.Variables {
+		if !hasField(md.Input(), v.FieldPath) {
+			return Binding{}, errors.Errorf("path variable %q matches no field of %s", v.FieldPath, md.Input().FullName())
+		}
+	}
+	if b.Body != "" && b.Body != "*" && md.Input().Fields().ByName(protoreflect.Name(b.Body)) == nil {
+		return Binding{}, errors.Errorf("body %q matches no field of %s", b.Body, md.Input().FullName())
+	}
+	if b.ResponseBody != "" && md.Output().Fields().ByName(protoreflect.Name(b.ResponseBody)) == nil {
+		return Binding{}, errors.Errorf("response body %q matches no field of %s", b.ResponseBody, md.Output().FullName())
+	}
+
+	return b, nil
+}
+
+// hasField reports whether the dotted path leads through singular message
+// fields to a field of md.
+func hasField(md protoreflect.MessageDescriptor, path string) bool {
+	for {
+		name, rest, nested := strings.Cut(path, ".")
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return false
+		}
+		if !nested {
+			return !fd.IsMap()
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return false
+		}
+		md, path = fd.Message(), rest
+	}
+}
+
+// ParseRoute splits "GET /v2/things" into its method and path.
+func ParseRoute(route string) (method, path string, ok bool) {
+	method, path, ok = strings.Cut(strings.TrimSpace(route), " ")
+	path = strings.TrimSpace(path)
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		return "", "", false
+	}
+	return strings.ToUpper(method), path, true
+}
+
+// ParseTarget splits "item.ThingService/GetThing" into the full name of
+// the service and the method name.
+func ParseTarget(target string) (protoreflect.FullName, protoreflect.Name, bool) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(target, "/"), "/")
+	if !ok || !protoreflect.FullName(service).IsValid() || !protoreflect.Name(method).IsValid() {
+		return "", "", false
+	}
+	return protoreflect.FullName(service), protoreflect.Name(method), true
+}
@@ -0,0 +1,203 @@
+package transcode
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Template is a parsed google.api.http path template, e.g.
+// "/v1/users/{user_id}/ratings" or "/v1/swaps/{swap_id}:accept".
+type Template struct {
+	Segments  []Segment
+	Variables []Variable
+	Verb      string
+}
+
+// Segment is one part of the path. Exactly one of Literal and Wildcard
+// is set; Wildcard is "*" for one segment or "**" for the rest of the path.
+type Segment struct {
+	Literal  string
+	Wildcard string
+}
+
+// Variable binds the segments from Start up to End to a request field.
+type Variable struct {
+	FieldPath string
+	Start     int
+	End       int
+}
+
+// ParseTemplate parses a path template. Besides the syntax rules of
+// google.api.http, a custom verb has to follow a variable or wildcard,
+// because the verb is matched against its value.
+func ParseTemplate(path string) (*Template, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.Errorf("path template %q must start with /", path)
+	}
+
+	t := &Template{}
+	rest := path[1:]
+
+	colon := strings.LastIndexByte(rest, ':')
+	if colon >= 0 && colon > strings.LastIndexByte(rest, '/') && colon > strings.LastIndexByte(rest, '}') {
+		t.Verb = rest[colon+1:]
+		rest = rest[:colon]
+		if t.Verb == "" {
+			return nil, errors.Errorf("path template %q has an empty verb", path)
+		}
+	}
+
+	for rest != "" {
+		if rest[0] == '{' {
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				return nil, errors.Errorf("path template %q has an unterminated variable", path)
+			}
+
+			field, pattern, found := strings.Cut(rest[1:end], "=")
+			if !found {
+				pattern = "*"
+			}
+			if !validFieldPath(field) {
+				return nil, errors.Errorf("path template %q has an invalid field path %q", path, field)
+			}
+
+			start := len(t.Segments)
+			for _, seg := range strings.Split(pattern, "/") {
+				err := t.add(seg)
+				if err != nil {
+					return nil, errors.Wrapf(err, "path template %q", path)
+				}
+			}
+			t.Variables = append(t.Variables, Variable{FieldPath: field, Start: start, End: len(t.Segments)})
+			rest = rest[end+1:]
+		} else {
+			end := strings.IndexByte(rest, '/')
+			if end < 0 {
+				end = len(rest)
+			}
+			err := t.add(rest[:end])
+			if err != nil {
+				return nil, errors.Wrapf(err, "path template %q", path)
+			}
+			rest = rest[end:]
+		}
+
+		if rest == "" {
+			break
+		}
+		if rest[0] != '/' || len(rest) == 1 {
+			return nil, errors.Errorf("path template %q is malformed", path)
+		}
+		rest = rest[1:]
+	}
+
+	for i, seg := range t.Segments {
+		if seg.Wildcard == "**" && i != len(t.Segments)-1 {
+			return nil, errors.Errorf("path template %q may only end in **", path)
+		}
+	}
+
+	if t.Verb != "" && (len(t.Segments) == 0 || t.Segments[len(t.Segments)-1].Wildcard == "") {
+		return nil, errors.Errorf("path template %q needs a variable before its verb", path)
+	}
+
+	return t, nil
+}
+
+func (t *Template) add(seg string) error {
+	switch {
+	case seg == "*" || seg == "**":
+		t.Segments = append(t.Segments, Segment{Wildcard: seg})
+	case seg == "" || strings.ContainsAny(seg, "{}*:"):
+		return errors.Errorf("invalid segment %q", seg)
+	default:
+		t.Segments = append(t.Segments, Segment{Literal: seg})
+	}
+	return nil
+}
+
+func validFieldPath(path string) bool {
+	for _, ident := range strings.Split(path, ".") {
+		if ident == "" {
+			return false
+		}
+		for i, r := range ident {
+			letter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+			if !letter && (i == 0 || r < '0' || r > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// GinPath returns the template as a gin route, without the verb. A
+// wildcard that makes up a whole variable is named after its field, so
+// that the field can be referred to as a path parameter, e.g. in access
+// rules; other wildcards get positional names.
+func (t *Template) GinPath() string {
+	if len(t.Segments) == 0 {
+		return "/"
+	}
+
+	var b strings.Builder
+	for i, seg := range t.Segments {
+		b.WriteByte('/')
+		switch seg.Wildcard {
+		case "":
+			b.WriteString(seg.Literal)
+		case "*":
+			b.WriteString(":" + t.paramName(i))
+		case "**":
+			b.WriteString("*" + t.paramName(i))
+		}
+	}
+	return b.String()
+}
+
+func (t *Template) paramName(i int) string {
+	for _, v := range t.Variables {
+		if v.Start == i && v.End == i+1 {
+			return v.FieldPath
+		}
+	}
+	return "_" + strconv.Itoa(i)
+}
+
+// MatchVerb reports whether a request routed to GinPath is meant for this
+// template, by checking that the last path parameter ends in the verb.
+// param returns gin path parameters by name.
+func (t *Template) MatchVerb(param func(string) string) bool {
+	if t.Verb == "" {
+		return true
+	}
+	last := len(t.Segments) - 1
+	value := strings.TrimPrefix(param(t.paramName(last)), "/")
+	return len(value) > len(t.Verb)+1 && strings.HasSuffix(value, ":"+t.Verb)
+}
+
+// Values returns the value of each variable, keyed by field path.
+func (t *Template) Values(param func(string) string) map[string]string {
+	values := map[string]string{}
+	for _, v := range t.Variables {
+		parts := make([]string, 0, v.End-v.Start)
+		for i := v.Start; i < v.End; i++ {
+			seg := t.Segments[i]
+			if seg.Wildcard == "" {
+				parts = append(parts, seg.Literal)
+				continue
+			}
+
+			value := strings.TrimPrefix(param(t.paramName(i)), "/")
+			if t.Verb != "" && i == len(t.Segments)-1 {
+				value = strings.TrimSuffix(value, ":"+t.Verb)
+			}
+			parts = append(parts, value)
+		}
+		values[v.FieldPath] = strings.Join(parts, "/")
+	}
+	return values
+}
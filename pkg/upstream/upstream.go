@@ -0,0 +1,61 @@
+package upstream
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Call is the outcome of a backend call made while serving a request.
+type Call struct {
+	Method string
+	Code   codes.Code
+}
+
+// Recorder remembers the last backend call of a request, so that it can
+// be reported once the request is done.
+type Recorder struct {
+	mu   sync.Mutex
+	call Call
+	ok   bool
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+func FromContext(ctx context.Context) (*Recorder, bool) {
+	r, ok := ctx.Value(contextKey{}).(*Recorder)
+	return r, ok
+}
+
+func (r *Recorder) Last() (Call, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.call, r.ok
+}
+
+func (r *Recorder) record(call Call) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.call = call
+	r.ok = true
+}
+
+// UnaryClientInterceptor stores the method and final status code of the
+// call in the Recorder found in the context, if any.
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	r, ok := FromContext(ctx)
+	if ok {
+		r.record(Call{Method: method, Code: status.Code(err)})
+	}
+	return err
+}
@@ -0,0 +1,15 @@
+#!/bin/bash
+# Fails when genproto/ differs from what gen-proto.sh makes of protos/. Without
+# protoc, go test ./genproto/ compares the descriptors the two describe.
+set -euo pipefail
+
+CURRENT_DIR=${1:-$(pwd)}
+TMP_DIR=$(mktemp -d)
+trap 'rm -rf ${TMP_DIR}' EXIT
+
+"$(dirname "$0")"/gen-proto.sh ${CURRENT_DIR} ${TMP_DIR}
+
+if ! diff -r ${CURRENT_DIR}/genproto ${TMP_DIR}/genproto; then
+  echo "genproto/ is out of date with protos/, run make proto-gen" >&2
+  exit 1
+fi
@@ -1,7 +1,31 @@
 #!/bin/bash
-CURRENT_DIR=$1
-rm -rf ${CURRENT_DIR}/genproto
+# Regenerates genproto/ from protos/, or writes it under OUT_DIR when given.
+# The protoc plugins are pinned and installed into bin/, so the output only
+# changes when the sources do. protoc itself is not go-installable: it has
+# to be the version below (set PROTOC to use a binary outside PATH).
+set -euo pipefail
+
+PROTOC_VERSION=3.12.4
+PROTOC_GEN_GO_VERSION=v1.34.2
+PROTOC_GEN_GO_GRPC_VERSION=v1.4.0
+
+CURRENT_DIR=${1:-$(pwd)}
+OUT_DIR=${2:-${CURRENT_DIR}}
+PROTOC=${PROTOC:-protoc}
+BIN_DIR=${CURRENT_DIR}/bin
+
+if [[ "$(${PROTOC} --version)" != "libprotoc ${PROTOC_VERSION}" ]]; then
+  echo "protoc ${PROTOC_VERSION} is required, found: $(${PROTOC} --version)" >&2
+  exit 1
+fi
+
+GOBIN=${BIN_DIR} go install google.golang.org/protobuf/cmd/protoc-gen-go@${PROTOC_GEN_GO_VERSION}
+GOBIN=${BIN_DIR} go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@${PROTOC_GEN_GO_GRPC_VERSION}
+
+rm -rf ${OUT_DIR}/genproto
 for x in $(find ${CURRENT_DIR}/protos/* -type d); do
-  protoc -I=${x} -I=${CURRENT_DIR}/protos -I /usr/local/go --go_out=${CURRENT_DIR} \
-   --go-grpc_out=${CURRENT_DIR} ${x}/*.proto
-done
\ No newline at end of file
+  ${PROTOC} -I=${x} -I=${CURRENT_DIR}/protos -I=${CURRENT_DIR}/third_party/googleapis \
+    --plugin=protoc-gen-go=${BIN_DIR}/protoc-gen-go \
+    --plugin=protoc-gen-go-grpc=${BIN_DIR}/protoc-gen-go-grpc \
+    --go_out=${OUT_DIR} --go-grpc_out=${OUT_DIR} ${x}/*.proto
+done